@@ -8,14 +8,27 @@ import (
 	config "central-cyclone/internal/config"
 	"central-cyclone/internal/gittool"
 	coordinator "central-cyclone/internal/handlers"
-	"central-cyclone/internal/upload"
 	"central-cyclone/internal/workspace"
+	"context"
+	"fmt"
+	"io"
 	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
 )
 
 var uploadSboms bool
+var analyzeDryRun bool
+var analyzeParallelism int
+var analyzeReportPath string
+var analyzeFailOn string
+var analyzeBundlePath string
+var analyzeSignKeyPath string
 
 // analyzeCmd represents the analyze command
 var analyzeCmd = &cobra.Command{
@@ -28,7 +41,59 @@ var analyzeCmd = &cobra.Command{
 			slog.Error("Could not get settings from context", "error", err)
 			return err
 		}
-		runAnalyzeCommand(settings)
+		if analyzeParallelism < 1 {
+			return fmt.Errorf("--parallel must be at least 1")
+		}
+		// Validated upfront, such that a typo does not surface after hours of analysis
+		if err := coordinator.ValidateFailOn(analyzeFailOn); err != nil {
+			return err
+		}
+		if analyzeBundlePath != "" && uploadSboms {
+			return fmt.Errorf("--bundle cannot be combined with --upload")
+		}
+		if analyzeSignKeyPath != "" && uploadSboms {
+			return fmt.Errorf("--sign-key cannot be combined with --upload")
+		}
+		if waitForProcessing && !uploadSboms {
+			return fmt.Errorf("--wait requires --upload")
+		}
+		if err := config.ValidateRepositories(settings); err != nil {
+			slog.Error("Configuration validation failed", "error", err)
+			return err
+		}
+		if analyzeDryRun {
+			return runAnalyzeDryRun(cmd, settings)
+		}
+		defer writeMetricsTextfile()
+
+		// Cancelled on shutdown, which kills all running generators
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		report, err := runAnalyzeCommand(ctx, settings)
+		if err != nil {
+			return err
+		}
+
+		printRunSummary(cmd.OutOrStdout(), report)
+		if analyzeReportPath != "" {
+			if err := report.WriteJSON(analyzeReportPath); err != nil {
+				slog.Error("Could not write report", "error", err)
+				return err
+			}
+		}
+		if analyzeBundlePath != "" {
+			if err := writeSbomBundle(analyzeBundlePath); err != nil {
+				slog.Error("Could not write bundle", "error", err)
+				return err
+			}
+		}
+
+		failed, _ := report.Failed(analyzeFailOn)
+		if failed {
+			failedCount, total := report.Counts()
+			return fmt.Errorf("%d of %d targets failed", failedCount, total)
+		}
 		return nil
 	},
 }
@@ -36,14 +101,65 @@ var analyzeCmd = &cobra.Command{
 func init() {
 	extensions.RequireConfig(analyzeCmd)
 	analyzeCmd.Flags().BoolVar(&uploadSboms, "upload", false, "Upload SBOMs to DependencyTrack after generation")
+	analyzeCmd.Flags().IntVar(&analyzeParallelism, "parallel", 1, "Number of repositories analyzed at the same time")
+	analyzeCmd.Flags().BoolVar(&analyzeDryRun, "dry-run", false, "Print the targets that would be analyzed, without analyzing or uploading")
+	analyzeCmd.Flags().StringVar(&analyzeReportPath, "report", "", "Write the results of all repos and targets as JSON to the given file")
+	analyzeCmd.Flags().StringVar(&analyzeFailOn, "fail-on", coordinator.FailOnAny, "Exit with a non-zero code if 'any' target failed, 'all' targets failed or 'never'")
+	analyzeCmd.Flags().StringVar(&analyzeSignKeyPath, "sign-key", "", "Ed25519 private key (PEM) to sign the saved SBOMs with")
+	analyzeCmd.Flags().StringVar(&analyzeBundlePath, "bundle", "", "Pack the saved SBOMs into a single .tar.gz file for the upload command")
+	addWaitFlags(analyzeCmd)
+	addMetricsTextfileFlag(analyzeCmd)
 }
 
-func runAnalyzeCommand(settings *config.Settings) {
+func writeSbomBundle(bundlePath string) error {
+	sbomsPath, err := workspace.GetSbomsFolderPath()
+	if err != nil {
+		return err
+	}
+	return workspace.WriteBundle(sbomsPath, bundlePath)
+}
 
+// runAnalyzeDryRun clones all repositories and prints the targets that would be analyzed
+func runAnalyzeDryRun(cmd *cobra.Command, settings *config.Settings) error {
 	workspaceHandler, err := workspace.CreateLocalWorkspace()
 	if err != nil {
 		slog.Error("Error creating workspace", "error", err)
-		return
+		return err
+	}
+	if err := workspaceHandler.Clear(); err != nil {
+		slog.Error("Error clearing workspace", "error", err)
+		return err
+	}
+
+	entries := coordinator.PlanAnalysis(settings, gittool.CreateLocalGitCloner(workspaceHandler))
+
+	out := cmd.OutOrStdout()
+	if len(entries) == 0 {
+		fmt.Fprintln(out, "No targets to analyze")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "REPO\tCOMMIT\tPROJECT\tTYPE\tDIRECTORY\tGENERATOR\tERROR")
+	failed := false
+	for _, entry := range entries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", entry.RepoUrl, entry.Commit, entry.Project, entry.ProjectType, entry.Directory, entry.Generator, entry.Error)
+		failed = failed || entry.Error != ""
+	}
+	w.Flush()
+
+	if failed {
+		return fmt.Errorf("dry run found targets that cannot be analyzed")
+	}
+	return nil
+}
+
+func runAnalyzeCommand(ctx context.Context, settings *config.Settings) (coordinator.RunReport, error) {
+
+	workspaceHandler, err := createAnalyzeWorkspace()
+	if err != nil {
+		slog.Error("Error creating workspace", "error", err)
+		return coordinator.RunReport{}, err
 	}
 
 	gitTool := gittool.CreateLocalGitCloner(workspaceHandler)
@@ -51,17 +167,61 @@ func runAnalyzeCommand(settings *config.Settings) {
 	err = workspaceHandler.Clear()
 	if err != nil {
 		slog.Error("Error clearing workspace", "error", err)
-		return
+		return coordinator.RunReport{}, err
 	}
 
 	if uploadSboms {
-		uploader, err := upload.CreateDependencyTrackUploader(settings)
+		uploader, err := createUploader(settings)
 		if err != nil {
 			slog.Error("Error creating uploader", "error", err)
-			return
+			return coordinator.RunReport{}, err
 		}
-		coordinator.AnalyzeAndUpload(settings, gitTool, workspaceHandler, uploader)
-	} else {
-		coordinator.AnalyzeAndSave(settings, gitTool, workspaceHandler)
+		return coordinator.AnalyzeAndUpload(ctx, settings, gitTool, workspaceHandler, uploader, analyzeParallelism), nil
+	}
+	return coordinator.AnalyzeAndSave(ctx, settings, gitTool, workspaceHandler, analyzeParallelism), nil
+}
+
+// createAnalyzeWorkspace creates the local workspace, which signs saved SBOMs if a sign key is configured
+func createAnalyzeWorkspace() (workspace.Workspace, error) {
+	if analyzeSignKeyPath == "" {
+		return workspace.CreateLocalWorkspace()
 	}
+	signingKey, err := workspace.LoadSigningKey(analyzeSignKeyPath)
+	if err != nil {
+		return nil, err
+	}
+	return workspace.CreateSigningLocalWorkspace(signingKey)
+}
+
+// printRunSummary prints a table with one row per target, or per repo if it could not be cloned
+func printRunSummary(out io.Writer, report coordinator.RunReport) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "REPO\tPROJECT\tTYPE\tSTATUS\tDURATION\tERROR")
+	for _, repo := range report.Repos {
+		if len(repo.Targets) == 0 {
+			fmt.Fprintf(w, "%s\t\t\t%s\t%s\t%s\n", repo.RepoUrl, repo.Status, formatSeconds(repo.DurationSeconds), repo.Error)
+			continue
+		}
+		for _, target := range repo.Targets {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", repo.RepoUrl, target.Project(), target.ProjectType, target.Status, formatSeconds(target.DurationSeconds), target.Error)
+		}
+	}
+	w.Flush()
+
+	failed, total := report.Counts()
+	fmt.Fprintf(out, "\n%d of %d targets succeeded in %s\n", total-failed, total, formatSeconds(report.DurationSeconds))
+
+	var results []projectUploadResult
+	for _, repo := range report.Repos {
+		for _, target := range repo.Targets {
+			if target.Upload != nil {
+				results = append(results, projectUploadResult{Project: target.Project(), Result: target.Upload})
+			}
+		}
+	}
+	printUploadResults(out, results)
+}
+
+func formatSeconds(seconds float64) string {
+	return (time.Duration(seconds * float64(time.Second))).Round(time.Second).String()
 }
@@ -6,12 +6,18 @@ import (
 	"central-cyclone/internal/config"
 	"central-cyclone/internal/gitops"
 	"central-cyclone/internal/gittool"
+	"central-cyclone/internal/metrics"
+	"central-cyclone/internal/server"
 	"central-cyclone/internal/upload"
+	"central-cyclone/internal/webhook"
 	"central-cyclone/internal/workspace"
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
 	"syscall"
+	"text/tabwriter"
 	"time"
 
 	"log/slog"
@@ -19,12 +25,28 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var httpAddress string
+var serveMetrics bool
+var enableWebhooks bool
+var dryRun bool
+var runOnce bool
+
 var GitOpsCmd = &cobra.Command{
 	Use:   "gitops",
 	Short: "Starts the gitops mode of central cyclone",
 	RunE: func(cmd *cobra.Command, args []string) error {
 
 		fmt.Println("⚠️ The gitops mode is currently in alpha mode")
+		if serveMetrics && httpAddress == "" {
+			return fmt.Errorf("--metrics requires --http-address")
+		}
+		webhookSecret := os.Getenv("GITOPS_WEBHOOK_SECRET")
+		if enableWebhooks && (httpAddress == "" || webhookSecret == "") {
+			return fmt.Errorf("--webhooks requires --http-address and the GITOPS_WEBHOOK_SECRET environment variable")
+		}
+		if enableWebhooks && runOnce {
+			return fmt.Errorf("--webhooks cannot be combined with --once")
+		}
 		settings, err := extensions.GetSettings(cmd)
 		if err != nil {
 			slog.Error("Could not get settings from context", "error", err)
@@ -44,36 +66,87 @@ var GitOpsCmd = &cobra.Command{
 			slog.Error("Configuration validation failed", "error", err)
 			return err
 		}
-		analyzer := analyzer.CdxgenAnalyzer{}
+		workFolderPath, err := workspace.GetWorkFolderPath()
+		if err != nil {
+			slog.Error("Could not get workfolder path", "error", err)
+			return err
+		}
+		stateStore := gitops.NewFileStateStore(configProvider.GetGitOpsStateFile(workFolderPath))
+
+		// Cancelled on shutdown, which stops the initialization and cancels all app changes currently handled
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		if dryRun {
+			return runDryRun(ctx, cmd.OutOrStdout(), configProvider, gitTool, ws, gitops.ReadOnlyStateStore{StateStore: stateStore}, settings.GitOpsRepos)
+		}
+
+		generators := analyzer.NewDefaultGeneratorRegistry()
 		uploader, err := upload.CreateDependencyTrackUploader(settings)
 		if err != nil {
 			slog.Error("Could not create DepependencyTrack Uploader", "error", err)
 			return err
 		}
 
-		createSbomHandler := gitops.NewCreateSbomChangeHandler(configProvider, gitTool, analyzer, uploader)
+		createSbomHandler := gitops.NewCreateSbomChangeHandler(configProvider, gitTool, generators, uploader)
+		syncer := gitops.NewSyncer(gitTool, ws, createSbomHandler, syncerOptions(configProvider, stateStore))
 
-		syncer := gitops.NewSyncer(gitTool, ws, createSbomHandler)
+		// Buffered, such that webhooks do not block while a reconcile is running
+		reconcileTriggers := make(chan string, 16)
 
-		err = syncer.Init(settings.GitOpsRepos)
+		// Started before the initialization, such that probes are answered while the repos are cloned
+		if httpAddress != "" {
+			httpServer := server.NewServer(httpAddress)
+			server.RegisterStatusRoutes(httpServer, syncer)
+			if serveMetrics {
+				httpServer.Handle("GET /metrics", metrics.Handler())
+			}
+			if enableWebhooks {
+				registerWebhooks(httpServer, webhookSecret, settings.GitOpsRepos, reconcileTriggers)
+			}
+			if err := httpServer.Start(); err != nil {
+				slog.Error("Could not start HTTP server", "error", err)
+				return err
+			}
+			defer func() {
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				if err := httpServer.Shutdown(shutdownCtx); err != nil {
+					slog.Warn("Could not shut down HTTP server", "error", err)
+				}
+			}()
+		}
+
+		err = syncer.Init(ctx, settings.GitOpsRepos)
 		if err != nil {
 			slog.Error("Failed to initialize syncer", "error", err)
 			return err
 		}
 
-		sigChan := make(chan os.Signal, 1)
-		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+		if runOnce {
+			if err := syncer.ReconcileOnce(ctx); err != nil {
+				slog.Error("Reconcile finished with errors", "error", err)
+				return err
+			}
+			slog.Info("Reconcile finished, exiting...")
+			return nil
+		}
 
 		refreshInterval := time.Duration(configProvider.GetGitOpsRefreshInterval()) * time.Minute
 		ticker := time.NewTicker(refreshInterval)
 		defer ticker.Stop()
 
+		// Failed app changes are logged by the syncer and retried on later runs
+		_ = syncer.Reconcile(ctx)
 		for {
-			syncer.Reconcile()
 			select {
 			case <-ticker.C:
-				// continue loop
-			case <-sigChan:
+				_ = syncer.Reconcile(ctx)
+			case repoUrl := <-reconcileTriggers:
+				if err := syncer.ReconcileRepo(ctx, repoUrl); err != nil {
+					slog.Warn("Error reconciling GitOps repo after webhook", "repo", repoUrl, "error", err)
+				}
+			case <-ctx.Done():
 				slog.Info("Received shutdown signal, exiting...")
 				return nil
 			}
@@ -81,6 +154,101 @@ var GitOpsCmd = &cobra.Command{
 	},
 }
 
+func syncerOptions(configProvider *config.ConfigProvider, stateStore gitops.StateStore) gitops.SyncerOptions {
+	maxAttempts, initialBackoff, maxBackoff := configProvider.GetGitOpsRetry()
+	return gitops.SyncerOptions{
+		StateStore:     stateStore,
+		MaxConcurrency: configProvider.GetGitOpsMaxConcurrency(),
+		RetryPolicy: gitops.RetryPolicy{
+			MaxAttempts:    maxAttempts,
+			InitialBackoff: initialBackoff,
+			MaxBackoff:     maxBackoff,
+		},
+		EnvironmentResolver: configProvider,
+	}
+}
+
+// runDryRun initializes the syncer and reconciles once with a plan handler. It prints the app changes that
+// would be analyzed and uploaded, without running an analysis, uploading or saving the state. Changes waiting
+// for their backoff and parked versions are planned as well and listed with their retry state.
+func runDryRun(ctx context.Context, out io.Writer, configProvider *config.ConfigProvider, gitTool gittool.Cloner, ws workspace.Workspace, stateStore gitops.StateStore, gitOpsRepos []config.GitOpsRepo) error {
+	planHandler := gitops.NewPlanChangeHandler(configProvider, gitTool)
+	syncer := gitops.NewSyncer(gitTool, ws, planHandler, syncerOptions(configProvider, stateStore))
+
+	if err := syncer.Init(ctx, gitOpsRepos); err != nil {
+		slog.Error("Failed to initialize syncer", "error", err)
+		return err
+	}
+	// Taken before the reconcile, which resets the retry state of the planned changes
+	retryStates := retryStatesByApp(syncer.Status())
+	reconcileErr := syncer.ReconcileAll(ctx)
+	printPlan(out, planHandler.Entries(), retryStates)
+
+	if reconcileErr != nil {
+		return fmt.Errorf("dry run found app changes that cannot be handled: %w", reconcileErr)
+	}
+	return nil
+}
+
+// appVersion identifies the version of an app environment
+type appVersion struct {
+	app, environment, version string
+}
+
+// retryStatesByApp returns the retry states of the skipped app changes by app, environment and version
+func retryStatesByApp(report gitops.StatusReport) map[appVersion]string {
+	retryStates := make(map[appVersion]string)
+	for _, repo := range report.GitOpsRepos {
+		for _, app := range repo.Apps {
+			if retryState := app.RetryState(); retryState != "" {
+				retryStates[appVersion{app.AppName, app.Environment, app.CurrentVersion}] = retryState
+			}
+		}
+	}
+	return retryStates
+}
+
+func printPlan(out io.Writer, entries []gitops.PlanEntry, retryStates map[appVersion]string) {
+	if len(entries) == 0 {
+		fmt.Fprintln(out, "No app changes to analyze")
+		return
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "APP\tENVIRONMENT\tVERSION\tCOMMIT\tPROJECT\tTYPE\tDIRECTORY\tGENERATOR\tSTATE\tERROR")
+	for _, entry := range entries {
+		retryState := retryStates[appVersion{entry.AppName, entry.Environment, entry.Version}]
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			entry.AppName, entry.Environment, entry.Version, entry.Commit, entry.Project, entry.ProjectType, entry.Directory, entry.Generator, retryState, entry.Error)
+	}
+	w.Flush()
+}
+
+// registerWebhooks registers the push webhook endpoints, which queue a reconcile of the pushed GitOps repo
+func registerWebhooks(httpServer *server.Server, secret string, gitOpsRepos []config.GitOpsRepo, reconcileTriggers chan<- string) {
+	repoUrls := make([]string, 0, len(gitOpsRepos))
+	for _, repo := range gitOpsRepos {
+		repoUrls = append(repoUrls, repo.Url)
+	}
+
+	receiver := webhook.NewReceiver(secret, repoUrls, func(repoUrl string) {
+		select {
+		case reconcileTriggers <- repoUrl:
+		default:
+			slog.Warn("Too many queued reconciles, dropping webhook trigger", "repo", repoUrl)
+		}
+	})
+
+	httpServer.Handle("POST /webhooks/github", receiver.GitHubHandler())
+	httpServer.Handle("POST /webhooks/gitlab", receiver.GitLabHandler())
+	httpServer.Handle("POST /webhooks/azure-devops", receiver.AzureDevOpsHandler())
+}
+
 func init() {
 	extensions.RequireConfig(GitOpsCmd)
+	GitOpsCmd.Flags().StringVar(&httpAddress, "http-address", "", "Optional address of the HTTP status and health API, e.g. :8080")
+	GitOpsCmd.Flags().BoolVar(&serveMetrics, "metrics", false, "Serve Prometheus metrics under /metrics of the HTTP API")
+	GitOpsCmd.Flags().BoolVar(&runOnce, "once", false, "Reconcile once and exit, with a non-zero exit code if an app change failed or is parked. Backoffs are not waited for.")
+	GitOpsCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the app changes that would be analyzed and uploaded, without analyzing, uploading or saving the state, and exit")
+	GitOpsCmd.Flags().BoolVar(&enableWebhooks, "webhooks", false, "Accept push webhooks under /webhooks/{github,gitlab,azure-devops} of the HTTP API")
 }
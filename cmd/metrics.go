@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"central-cyclone/internal/metrics"
+	"log/slog"
+
+	"github.com/spf13/cobra"
+)
+
+var metricsTextfile string
+
+// addMetricsTextfileFlag adds the flag to write metrics for the node-exporter textfile collector
+func addMetricsTextfileFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&metricsTextfile, "metrics-textfile", "", "Optional path of a node-exporter textfile (*.prom) to write metrics to")
+}
+
+// writeMetricsTextfile writes the collected metrics, if a textfile was configured
+func writeMetricsTextfile() {
+	if metricsTextfile == "" {
+		return
+	}
+	if err := metrics.WriteTextfile(metricsTextfile); err != nil {
+		slog.Error("Could not write metrics textfile", "error", err)
+		return
+	}
+	slog.Info("📈 Wrote metrics", "path", metricsTextfile)
+}
@@ -6,6 +6,7 @@ package cmd
 import (
 	"central-cyclone/cmd/dtrack"
 	"central-cyclone/cmd/gitops"
+	"central-cyclone/internal/buildinfo"
 	"os"
 
 	"github.com/spf13/cobra"
@@ -13,9 +14,10 @@ import (
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
-	Use:   "central-cyclone",
-	Short: "A small helper for central automated SBOM creation",
-	Long:  `central-cyclone allows you to create SBOMS for multiple defined repositories.`,
+	Use:     "central-cyclone",
+	Short:   "A small helper for central automated SBOM creation",
+	Long:    `central-cyclone allows you to create SBOMS for multiple defined repositories.`,
+	Version: buildinfo.Version,
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -1,12 +1,16 @@
 package cmd
 
 import (
-	"context"
+	"crypto/ed25519"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
 	"sync"
+	"syscall"
 
 	"central-cyclone/cmd/extensions"
 	"central-cyclone/internal/models"
-	"central-cyclone/internal/upload"
 	"central-cyclone/internal/workspace"
 	"log/slog"
 
@@ -14,6 +18,8 @@ import (
 )
 
 var sbomFolder string
+var sbomBundle string
+var verifyKeyPath string
 
 var uploadCmd = &cobra.Command{
 	Use:   "upload",
@@ -25,10 +31,24 @@ var uploadCmd = &cobra.Command{
 			return err
 		}
 
+		defer writeMetricsTextfile()
+
 		sbomNamer := workspace.DefaultSBOMNamer{}
 		repoMapper := workspace.DefaultRepoMapper{}
 
-		readonlyWorkspace := workspace.CreateLocalReadonlySbomWorkspace(sbomFolder, sbomNamer, repoMapper)
+		var verifyKey ed25519.PublicKey
+		if verifyKeyPath != "" {
+			verifyKey, err = workspace.LoadVerifyKey(verifyKeyPath)
+			if err != nil {
+				slog.Error("Could not load verify key", "error", err)
+				return err
+			}
+		}
+
+		readonlyWorkspace := workspace.CreateLocalReadonlySbomWorkspace(sbomFolder, sbomNamer, repoMapper, verifyKey)
+		if sbomBundle != "" {
+			readonlyWorkspace = workspace.CreateBundleReadonlySbomWorkspace(sbomBundle, verifyKey)
+		}
 
 		sboms, err := readonlyWorkspace.ReadSboms()
 		if err != nil {
@@ -36,13 +56,15 @@ var uploadCmd = &cobra.Command{
 			return err
 		}
 
-		uploader, err := upload.CreateDependencyTrackUploader(settings)
+		uploader, err := createUploader(settings)
 		if err != nil {
 			slog.Error("Error creating uploader", "error", err)
 			return err
 		}
 
-		ctx := context.Background()
+		// Cancelled on shutdown, which stops running uploads and the waiting for their processing
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
 
 		// Limit concurrent uploads to 5
 		maxConcurrency := 5
@@ -50,6 +72,7 @@ var uploadCmd = &cobra.Command{
 
 		var wg sync.WaitGroup
 		var uploadErr error
+		var results []projectUploadResult
 		var mu sync.Mutex
 
 		for _, sbom := range sboms {
@@ -61,11 +84,16 @@ var uploadCmd = &cobra.Command{
 				semaphore <- struct{}{}
 				defer func() { <-semaphore }()
 
-				if err := uploader.UploadSBOM(ctx, s); err != nil {
-					mu.Lock()
+				result, err := uploader.UploadSBOM(ctx, s)
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
 					slog.Error("Error uploading SBOM", "error", err)
 					uploadErr = err
-					mu.Unlock()
+					return
+				}
+				if result != nil {
+					results = append(results, projectUploadResult{Project: models.ProjectLabel(s.ProjectId, s.ProjectName, s.ProjectVersion), Result: result})
 				}
 			}(sbom)
 
@@ -74,6 +102,9 @@ var uploadCmd = &cobra.Command{
 		wg.Wait()
 		close(semaphore)
 
+		slices.SortFunc(results, func(a, b projectUploadResult) int { return strings.Compare(a.Project, b.Project) })
+		printUploadResults(cmd.OutOrStdout(), results)
+
 		return uploadErr
 	},
 }
@@ -82,4 +113,9 @@ func init() {
 	extensions.RequireConfig(uploadCmd)
 	uploadCmd.Flags().StringP("config", "c", "./config.json", "Path to the configuration file")
 	uploadCmd.Flags().StringVar(&sbomFolder, "sboms-dir", "/sboms", "Directory containg the sboms to upload")
+	uploadCmd.Flags().StringVar(&sbomBundle, "bundle", "", "Bundle (.tar.gz) containing the sboms to upload, instead of a directory")
+	uploadCmd.Flags().StringVar(&verifyKeyPath, "verify-key", "", "Ed25519 public key (PEM), refuses sboms without a signed manifest listing exactly them")
+	addWaitFlags(uploadCmd)
+	uploadCmd.MarkFlagsMutuallyExclusive("sboms-dir", "bundle")
+	addMetricsTextfileFlag(uploadCmd)
 }
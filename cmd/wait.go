@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"central-cyclone/internal/config"
+	"central-cyclone/internal/upload"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var waitForProcessing bool
+var waitTimeout time.Duration
+
+// projectUploadResult is the result of the SBOM uploaded to a single project
+type projectUploadResult struct {
+	Project string
+	Result  *upload.UploadResult
+}
+
+// addWaitFlags adds the flags to wait for DependencyTrack to process the uploaded SBOMs
+func addWaitFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&waitForProcessing, "wait", false, "Wait for DependencyTrack to process the uploaded SBOMs and print findings and metrics per project")
+	cmd.Flags().DurationVar(&waitTimeout, "wait-timeout", 10*time.Minute, "Maximum time to wait for the processing of a single SBOM")
+}
+
+// createUploader creates the DependencyTrack uploader, which waits for the processing if --wait is set
+func createUploader(settings *config.Settings) (upload.Uploader, error) {
+	if !waitForProcessing {
+		return upload.CreateDependencyTrackUploader(settings)
+	}
+	if waitTimeout <= 0 {
+		return nil, fmt.Errorf("--wait-timeout must be positive")
+	}
+	return upload.CreateWaitingDependencyTrackUploader(settings, waitTimeout)
+}
+
+// printUploadResults prints a table with the findings and metrics of every project
+func printUploadResults(out io.Writer, results []projectUploadResult) {
+	if len(results) == 0 {
+		return
+	}
+	fmt.Fprintln(out)
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROJECT\tCRITICAL\tHIGH\tMEDIUM\tLOW\tINFO\tUNASSIGNED\tPOLICY VIOLATIONS\tCOMPONENTS\tRISK SCORE")
+	for _, r := range results {
+		findings, metrics := r.Result.Findings, r.Result.Metrics
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%g\n", r.Project, findings.Critical, findings.High, findings.Medium, findings.Low, findings.Info, findings.Unassigned,
+			metrics.PolicyViolationsTotal, metrics.Components, metrics.InheritedRiskScore)
+	}
+	w.Flush()
+}
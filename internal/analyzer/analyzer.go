@@ -2,55 +2,168 @@ package analyzer
 
 import (
 	"central-cyclone/internal/gittool"
+	"central-cyclone/internal/metrics"
 	"central-cyclone/internal/models"
+	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"strings"
+	"time"
 )
 
+// Analyzer creates the SBOM of a target. The generator process and all its children are killed, once the context is done.
 type Analyzer interface {
-	AnalyzeProject(repo gittool.ClonedRepo, target *ScanTarget) (models.Sbom, error)
+	AnalyzeProject(ctx context.Context, repo gittool.ClonedRepo, target *ScanTarget) (models.Sbom, error)
 }
 
 type ScanTarget struct {
-	ProjectId   string
-	ProjectType string
-	Directory   *string
+	ProjectId      string
+	ProjectName    string // Identifies the project together with the version, if no id is set
+	ProjectVersion string
+	ProjectTypes   []string // Multiple types are merged by cdxgen into a single SBOM
+	Directory      *string
+	Generator      string          // Name of the generator in the registry, defaults to cdxgen
+	Command        *CommandOptions // Required for the command generator
+	Cdxgen         *CdxgenOptions  // Optional for the cdxgen generator
+	Timeout        time.Duration   // Maximum duration of a run of any generator, zero if unlimited
+	// Passed on to the upload, DependencyTrack creates a missing project with its parent and tags if autoCreate is set
+	AutoCreate bool
+	Parent     *models.ProjectParent
+	Tags       []string
 }
 
-type CdxgenAnalyzer struct{}
+// Project describes the DependencyTrack project of the target, its id or name@version
+func (t *ScanTarget) Project() string {
+	return models.ProjectLabel(t.ProjectId, t.ProjectName, t.ProjectVersion)
+}
+
+// ValidateProject checks that the target identifies its DependencyTrack project by id or by name and version
+func (t *ScanTarget) ValidateProject() error {
+	if t.ProjectId == "" && (t.ProjectName == "" || t.ProjectVersion == "") {
+		return fmt.Errorf("either a projectId or a project name and version are required")
+	}
+	if t.Parent != nil {
+		if !t.AutoCreate {
+			return fmt.Errorf("a parent project requires autoCreate, as it is only linked on creation")
+		}
+		if t.Parent.Uuid == "" && t.Parent.Name == "" {
+			return fmt.Errorf("the parent project requires a uuid or a name")
+		}
+	}
+	return nil
+}
+
+// ProjectType returns the project types separated by commas
+func (t *ScanTarget) ProjectType() string {
+	return strings.Join(t.ProjectTypes, ",")
+}
+
+// GetGenerator returns the configured generator, defaulting to cdxgen
+func (t *ScanTarget) GetGenerator() string {
+	if t.Generator == "" {
+		return GeneratorCdxgen
+	}
+	return t.Generator
+}
+
+// timeout returns the maximum duration of the generator run, zero if it is unlimited.
+// A cdxgen timeout overrides the timeout of the target.
+func (t *ScanTarget) timeout() time.Duration {
+	if t.GetGenerator() == GeneratorCdxgen && t.Cdxgen != nil && t.Cdxgen.Timeout > 0 {
+		return t.Cdxgen.Timeout
+	}
+	return t.Timeout
+}
+
+// relativeDirectory returns the directory of the target within the repo, or . for the repo root
+func (t *ScanTarget) relativeDirectory() string {
+	if t.Directory == nil || *t.Directory == "" {
+		return "."
+	}
+	return *t.Directory
+}
 
-func (a CdxgenAnalyzer) AnalyzeProject(repo gittool.ClonedRepo, target *ScanTarget) (models.Sbom, error) {
+// AnalyzeProject creates the SBOM of the target with the generator selected by the target
+func (r *GeneratorRegistry) AnalyzeProject(ctx context.Context, repo gittool.ClonedRepo, target *ScanTarget) (sbom models.Sbom, err error) {
+	start := time.Now()
+	defer func() {
+		metrics.AnalysisDuration.WithLabelValues(target.ProjectType(), metrics.Result(err)).Observe(time.Since(start).Seconds())
+		if err != nil {
+			metrics.AnalysisFailures.WithLabelValues(target.ProjectType()).Inc()
+		}
+	}()
 
-	sbomFileName := fmt.Sprintf("sbom_%s.json", target.ProjectType)
-	sbomFilePath := filepath.Join(repo.Path, sbomFileName)
+	generatorName := target.GetGenerator()
+	if err := r.Validate(target); err != nil {
+		return models.Sbom{}, fmt.Errorf("%s: %w", generatorName, err)
+	}
+	generator, _ := r.Get(generatorName)
 
-	cmd := exec.Command("cdxgen", "--fail-on-error", "-t", target.ProjectType, "-o", sbomFileName, "--spec-version", "1.6")
+	// Written outside of the repo into its own folder, such that concurrent runs never share a file name
+	outputDir, err := os.MkdirTemp("", "central-cyclone-sbom-*")
+	if err != nil {
+		return models.Sbom{}, fmt.Errorf("failed to create output folder: %w", err)
+	}
+	defer os.RemoveAll(outputDir)
+	sbomFilePath := filepath.Join(outputDir, "sbom.json")
 
-	if target.Directory != nil {
-		cmd.Args = append(cmd.Args, *target.Directory)
+	if timeout := target.timeout(); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
 	}
 
-	cmd.Dir = repo.Path
+	cmd := generator.Command(ctx, repo.Path, sbomFilePath, target)
+	killProcessGroupOnCancel(cmd)
 	output, err := cmd.CombinedOutput()
+	if ctx.Err() != nil {
+		if ctx.Err() == context.DeadlineExceeded && target.timeout() > 0 {
+			slog.Error("Creating sbom timed out", "generator", generatorName, "output", string(output), "timeout", target.timeout())
+			return models.Sbom{}, fmt.Errorf("%s timed out after %s\nOutput: %s", generatorName, target.timeout(), string(output))
+		}
+		slog.Warn("Creating sbom cancelled", "generator", generatorName)
+		return models.Sbom{}, fmt.Errorf("%s cancelled: %w", generatorName, ctx.Err())
+	}
 	if err != nil {
-		slog.Error("Creating sbom with cdxgen failed: ", "output", string(output), "error", err)
-		return models.Sbom{}, fmt.Errorf("cdxgen failed: %v\nOutput: %s", err, string(output))
+		slog.Error("Creating sbom failed", "generator", generatorName, "output", string(output), "error", err)
+		return models.Sbom{}, fmt.Errorf("%s failed: %v\nOutput: %s", generatorName, err, string(output))
 	}
 
 	bytes, err := os.ReadFile(sbomFilePath)
-	sbomstring := string(bytes)
-	os.Remove(sbomFilePath)
-
 	if err != nil {
-		slog.Error("Failed to read created sbom file", "path", sbomFileName)
+		slog.Error("Failed to read created sbom file", "generator", generatorName, "path", sbomFilePath)
 		return models.Sbom{}, fmt.Errorf("failed to read sbom file: %v", err)
 	}
+	if err := validateCycloneDx(bytes); err != nil {
+		return models.Sbom{}, fmt.Errorf("%s created an invalid sbom: %w", generatorName, err)
+	}
+
 	return models.Sbom{
-		ProjectId:   target.ProjectId,
-		ProjectType: target.ProjectType,
-		Data:        sbomstring,
+		ProjectId:      target.ProjectId,
+		ProjectName:    target.ProjectName,
+		ProjectVersion: target.ProjectVersion,
+		ProjectType:    target.ProjectType(),
+		AutoCreate:     target.AutoCreate,
+		Parent:         target.Parent,
+		Tags:           target.Tags,
+		Generator:      generatorName,
+		Data:           string(bytes),
 	}, nil
 }
+
+// validateCycloneDx ensures that a generator wrote a CycloneDX JSON document
+func validateCycloneDx(data []byte) error {
+	var bom struct {
+		BomFormat string `json:"bomFormat"`
+	}
+	if err := json.Unmarshal(data, &bom); err != nil {
+		return fmt.Errorf("not a JSON document: %w", err)
+	}
+	if bom.BomFormat != "CycloneDX" {
+		return fmt.Errorf("expected bomFormat CycloneDX, got '%s'", bom.BomFormat)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package analyzer
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+const defaultCdxgenSpecVersion = "1.6"
+
+var supportedCdxgenSpecVersions = []string{"1.4", "1.5", "1.6", "1.7"}
+
+// cdxgenManagedFlags are set by the CdxgenGenerator itself and cannot be passed as args
+var cdxgenManagedFlags = []string{"-t", "--type", "-o", "--output", "--spec-version"}
+
+// CdxgenOptions are passed through to cdxgen
+type CdxgenOptions struct {
+	Args        []string          // Additional args, e.g. --required-only or --profile research
+	Env         map[string]string // Additional environment variables, e.g. FETCH_LICENSE=true
+	SpecVersion string            // CycloneDX spec version, defaults to 1.6
+	Timeout     time.Duration     // Maximum duration of a cdxgen run, zero if unlimited
+}
+
+func (o *CdxgenOptions) getSpecVersion() string {
+	if o.SpecVersion == "" {
+		return defaultCdxgenSpecVersion
+	}
+	return o.SpecVersion
+}
+
+// environ returns the environment variables in the form key=value, sorted by key
+func (o *CdxgenOptions) environ() []string {
+	var env []string
+	for _, key := range slices.Sorted(maps.Keys(o.Env)) {
+		env = append(env, key+"="+o.Env[key])
+	}
+	return env
+}
+
+func (o *CdxgenOptions) validate() error {
+	if o == nil {
+		return nil
+	}
+	if !slices.Contains(supportedCdxgenSpecVersions, o.getSpecVersion()) {
+		return fmt.Errorf("unsupported cdxgen spec version '%s', expected one of %s", o.SpecVersion, strings.Join(supportedCdxgenSpecVersions, ", "))
+	}
+	for _, arg := range o.Args {
+		flag, _, _ := strings.Cut(arg, "=")
+		if slices.Contains(cdxgenManagedFlags, flag) {
+			return fmt.Errorf("cdxgen arg '%s' is set by central cyclone and cannot be overridden", flag)
+		}
+	}
+	for key := range o.Env {
+		if key == "" || strings.ContainsAny(key, "= ") {
+			return fmt.Errorf("invalid cdxgen environment variable name '%s'", key)
+		}
+	}
+	if o.Timeout < 0 {
+		return fmt.Errorf("cdxgen timeout must not be negative")
+	}
+	return nil
+}
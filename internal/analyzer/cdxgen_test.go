@@ -0,0 +1,85 @@
+package analyzer
+
+import (
+	"central-cyclone/internal/gittool"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCdxgenGenerator_Options(t *testing.T) {
+	argsFile := installFakeGenerator(t, "cdxgen", fakeSbom)
+	repoPath := t.TempDir()
+	directory := "web"
+	target := &ScanTarget{ProjectId: "p1", ProjectTypes: []string{"java", "js"}, Directory: &directory, Cdxgen: &CdxgenOptions{
+		Args:        []string{"--required-only", "--profile", "research"},
+		Env:         map[string]string{"FETCH_LICENSE": "true"},
+		SpecVersion: "1.5",
+	}}
+
+	if _, err := NewDefaultGeneratorRegistry().AnalyzeProject(t.Context(), gittool.ClonedRepo{Path: repoPath}, target); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, args := readArgs(t, argsFile)
+	args, outputPath := replaceOutputPath(args)
+	want := "--fail-on-error -t java -t js -o {output} --spec-version 1.5 --required-only --profile research web"
+	if got := strings.Join(args, " "); got != want {
+		t.Errorf("expected args %q, got %q", want, got)
+	}
+
+	cmd := CdxgenGenerator{}.Command(t.Context(), repoPath, outputPath, target)
+	if !slices.Contains(cmd.Env, "FETCH_LICENSE=true") {
+		t.Errorf("expected FETCH_LICENSE in environment")
+	}
+	if !slices.Contains(cmd.Env, "PATH="+os.Getenv("PATH")) {
+		t.Errorf("expected the environment of the process to be inherited")
+	}
+}
+
+func TestCdxgenGenerator_Timeout(t *testing.T) {
+	binDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(binDir, "cdxgen"), []byte("#!/bin/sh\nexec sleep 10\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	target := &ScanTarget{ProjectId: "p1", ProjectTypes: []string{"node"}, Cdxgen: &CdxgenOptions{Timeout: 100 * time.Millisecond}}
+	start := time.Now()
+	_, err := NewDefaultGeneratorRegistry().AnalyzeProject(t.Context(), gittool.ClonedRepo{Path: t.TempDir()}, target)
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expected timeout error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected cdxgen to be killed, took %s", elapsed)
+	}
+}
+
+func TestGeneratorRegistry_Validate_CdxgenOptions(t *testing.T) {
+	tests := []struct {
+		name      string
+		target    ScanTarget
+		wantError bool
+	}{
+		{name: "no options", target: ScanTarget{}},
+		{name: "valid options", target: ScanTarget{Cdxgen: &CdxgenOptions{Args: []string{"--no-recurse", "--exclude", "**/test/**"}, Env: map[string]string{"CDXGEN_DEBUG_MODE": "debug"}, SpecVersion: "1.4", Timeout: time.Minute}}},
+		{name: "unsupported spec version", target: ScanTarget{Cdxgen: &CdxgenOptions{SpecVersion: "2.0"}}, wantError: true},
+		{name: "managed flag", target: ScanTarget{Cdxgen: &CdxgenOptions{Args: []string{"--output", "other.json"}}}, wantError: true},
+		{name: "managed flag with value", target: ScanTarget{Cdxgen: &CdxgenOptions{Args: []string{"--spec-version=1.5"}}}, wantError: true},
+		{name: "invalid env name", target: ScanTarget{Cdxgen: &CdxgenOptions{Env: map[string]string{"A=B": "c"}}}, wantError: true},
+		{name: "negative timeout", target: ScanTarget{Cdxgen: &CdxgenOptions{Timeout: -time.Second}}, wantError: true},
+		{name: "options for other generator", target: ScanTarget{Generator: GeneratorSyft, Cdxgen: &CdxgenOptions{}}, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewDefaultGeneratorRegistry().Validate(&tt.target)
+			if tt.wantError != (err != nil) {
+				t.Errorf("expected error %v, got %v", tt.wantError, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Names of the built-in generators
+const (
+	GeneratorCdxgen         = "cdxgen"
+	GeneratorSyft           = "syft"
+	GeneratorCycloneDxGomod = "cyclonedx-gomod"
+	GeneratorCycloneDxNpm   = "cyclonedx-npm"
+	GeneratorCommand        = "command"
+)
+
+// Generator builds the command, which creates a CycloneDX JSON SBOM of the target at outputPath.
+// The command is killed, once the context is done.
+type Generator interface {
+	// Validate checks the generator specific options of the target
+	Validate(target *ScanTarget) error
+	Command(ctx context.Context, repoPath, outputPath string, target *ScanTarget) *exec.Cmd
+}
+
+// GeneratorRegistry holds the generators by name. It implements Analyzer by running the generator
+// selected by the scan target.
+type GeneratorRegistry struct {
+	generators map[string]Generator
+}
+
+func NewGeneratorRegistry() *GeneratorRegistry {
+	return &GeneratorRegistry{generators: make(map[string]Generator)}
+}
+
+// NewDefaultGeneratorRegistry creates a registry containing all built-in generators
+func NewDefaultGeneratorRegistry() *GeneratorRegistry {
+	registry := NewGeneratorRegistry()
+	registry.Register(GeneratorCdxgen, CdxgenGenerator{})
+	registry.Register(GeneratorSyft, SyftGenerator{})
+	registry.Register(GeneratorCycloneDxGomod, CycloneDxGomodGenerator{})
+	registry.Register(GeneratorCycloneDxNpm, CycloneDxNpmGenerator{})
+	registry.Register(GeneratorCommand, CommandGenerator{})
+	return registry
+}
+
+// Register adds the generator, replacing an existing one with the same name
+func (r *GeneratorRegistry) Register(name string, generator Generator) {
+	r.generators[name] = generator
+}
+
+func (r *GeneratorRegistry) Get(name string) (Generator, error) {
+	generator, exists := r.generators[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown generator '%s', expected one of %s", name, strings.Join(r.Names(), ", "))
+	}
+	return generator, nil
+}
+
+// Validate checks that the generator of the target is registered and its options are valid
+func (r *GeneratorRegistry) Validate(target *ScanTarget) error {
+	generatorName := target.GetGenerator()
+	generator, err := r.Get(generatorName)
+	if err != nil {
+		return err
+	}
+	if target.Cdxgen != nil && generatorName != GeneratorCdxgen {
+		return fmt.Errorf("cdxgen options cannot be used with generator '%s'", generatorName)
+	}
+	if target.Timeout < 0 {
+		return fmt.Errorf("timeout must not be negative")
+	}
+	return generator.Validate(target)
+}
+
+// Names returns the names of all registered generators in alphabetical order
+func (r *GeneratorRegistry) Names() []string {
+	names := make([]string, 0, len(r.generators))
+	for name := range r.generators {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// CdxgenGenerator runs cdxgen for the project type of the target, applying the cdxgen options of the target
+type CdxgenGenerator struct{}
+
+func (CdxgenGenerator) Validate(target *ScanTarget) error {
+	if slices.Contains(target.ProjectTypes, "") {
+		return fmt.Errorf("project types must not be empty")
+	}
+	return target.Cdxgen.validate()
+}
+
+func (CdxgenGenerator) Command(ctx context.Context, repoPath, outputPath string, target *ScanTarget) *exec.Cmd {
+	options := target.Cdxgen
+	if options == nil {
+		options = &CdxgenOptions{}
+	}
+
+	cmd := exec.CommandContext(ctx, "cdxgen", "--fail-on-error")
+	for _, projectType := range target.ProjectTypes {
+		cmd.Args = append(cmd.Args, "-t", projectType)
+	}
+	cmd.Args = append(cmd.Args, "-o", outputPath, "--spec-version", options.getSpecVersion())
+	cmd.Args = append(cmd.Args, options.Args...)
+	if target.Directory != nil {
+		cmd.Args = append(cmd.Args, *target.Directory)
+	}
+	cmd.Dir = repoPath
+	if len(options.Env) > 0 {
+		cmd.Env = append(os.Environ(), options.environ()...)
+	}
+	return cmd
+}
+
+// SyftGenerator scans the directory of the target with syft
+type SyftGenerator struct{}
+
+func (SyftGenerator) Validate(target *ScanTarget) error {
+	return nil
+}
+
+func (SyftGenerator) Command(ctx context.Context, repoPath, outputPath string, target *ScanTarget) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "syft", "scan", "dir:"+target.relativeDirectory(), "-o", "cyclonedx-json="+outputPath)
+	cmd.Dir = repoPath
+	return cmd
+}
+
+// CycloneDxGomodGenerator creates the SBOM of the Go module in the directory of the target
+type CycloneDxGomodGenerator struct{}
+
+func (CycloneDxGomodGenerator) Validate(target *ScanTarget) error {
+	return nil
+}
+
+func (CycloneDxGomodGenerator) Command(ctx context.Context, repoPath, outputPath string, target *ScanTarget) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "cyclonedx-gomod", "mod", "-json", "-output", outputPath, target.relativeDirectory())
+	cmd.Dir = repoPath
+	return cmd
+}
+
+// CycloneDxNpmGenerator creates the SBOM of the npm project in the directory of the target
+type CycloneDxNpmGenerator struct{}
+
+func (CycloneDxNpmGenerator) Validate(target *ScanTarget) error {
+	return nil
+}
+
+func (CycloneDxNpmGenerator) Command(ctx context.Context, repoPath, outputPath string, target *ScanTarget) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "cyclonedx-npm", "--output-format", "JSON", "--output-file", outputPath)
+	cmd.Dir = filepath.Join(repoPath, target.relativeDirectory())
+	return cmd
+}
+
+// CommandOptions configure the command generator. The args may contain the placeholders
+// {output}, {dir}, {repo}, {type} and {projectId}. Multiple project types are separated by commas in {type}.
+type CommandOptions struct {
+	Executable string
+	Args       []string
+}
+
+// CommandGenerator runs an arbitrary executable, which writes a CycloneDX JSON SBOM to {output}
+type CommandGenerator struct{}
+
+func (CommandGenerator) Validate(target *ScanTarget) error {
+	if target.Command == nil || target.Command.Executable == "" {
+		return fmt.Errorf("the command generator requires an executable")
+	}
+	return nil
+}
+
+func (CommandGenerator) Command(ctx context.Context, repoPath, outputPath string, target *ScanTarget) *exec.Cmd {
+
+	replacer := strings.NewReplacer(
+		"{output}", outputPath,
+		"{dir}", target.relativeDirectory(),
+		"{repo}", repoPath,
+		"{type}", target.ProjectType(),
+		"{projectId}", target.ProjectId,
+	)
+	args := make([]string, len(target.Command.Args))
+	for i, arg := range target.Command.Args {
+		args[i] = replacer.Replace(arg)
+	}
+
+	cmd := exec.CommandContext(ctx, target.Command.Executable, args...)
+	cmd.Dir = repoPath
+	return cmd
+}
@@ -0,0 +1,244 @@
+package analyzer
+
+import (
+	"central-cyclone/internal/gittool"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const fakeSbom = `{"bomFormat":"CycloneDX","specVersion":"1.6"}`
+
+// installFakeGenerator puts an executable with the given name on the PATH. It records its args and working directory,
+// and writes the content to the path following the output flag, or fails if the content is empty.
+func installFakeGenerator(t *testing.T, name, content string) (argsFile string) {
+	t.Helper()
+	binDir := t.TempDir()
+	argsFile = filepath.Join(t.TempDir(), "args")
+
+	script := `#!/bin/sh
+pwd > "` + argsFile + `"
+for arg in "$@"; do echo "$arg" >> "` + argsFile + `"; done
+if [ -z '` + content + `' ]; then echo "generator failed" >&2; exit 3; fi
+output=""
+previous=""
+for arg in "$@"; do
+	case "$previous" in -o|-output|--output|--output-file) output="$arg" ;; esac
+	case "$arg" in cyclonedx-json=*) output="${arg#cyclonedx-json=}" ;; esac
+	previous="$arg"
+done
+printf '%s' '` + content + `' > "$output"
+`
+	if err := os.WriteFile(filepath.Join(binDir, name), []byte(script), 0o755); err != nil {
+		t.Fatalf("failed to write fake generator: %v", err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return argsFile
+}
+
+func readArgs(t *testing.T, argsFile string) (dir string, args []string) {
+	t.Helper()
+	data, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatalf("generator was not run: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	return lines[0], lines[1:]
+}
+
+func TestGeneratorRegistry_AnalyzeProject(t *testing.T) {
+	directory := "services/api"
+
+	tests := []struct {
+		name       string
+		executable string
+		target     ScanTarget
+		wantDir    string
+		wantArgs   []string
+	}{
+		{
+			name:       "cdxgen by default",
+			executable: "cdxgen",
+			target:     ScanTarget{ProjectId: "p1", ProjectTypes: []string{"node"}},
+			wantArgs:   []string{"--fail-on-error", "-t", "node", "-o", "{output}", "--spec-version", "1.6"},
+		},
+		{
+			name:       "syft",
+			executable: "syft",
+			target:     ScanTarget{ProjectId: "p1", ProjectTypes: []string{"java"}, Directory: &directory, Generator: GeneratorSyft},
+			wantArgs:   []string{"scan", "dir:services/api", "-o", "cyclonedx-json={output}"},
+		},
+		{
+			name:       "cyclonedx-gomod",
+			executable: "cyclonedx-gomod",
+			target:     ScanTarget{ProjectId: "p1", ProjectTypes: []string{"go"}, Generator: GeneratorCycloneDxGomod},
+			wantArgs:   []string{"mod", "-json", "-output", "{output}", "."},
+		},
+		{
+			name:       "cyclonedx-npm",
+			executable: "cyclonedx-npm",
+			target:     ScanTarget{ProjectId: "p1", ProjectTypes: []string{"node"}, Directory: &directory, Generator: GeneratorCycloneDxNpm},
+			wantDir:    directory,
+			wantArgs:   []string{"--output-format", "JSON", "--output-file", "{output}"},
+		},
+		{
+			name:       "command",
+			executable: "trivy",
+			target: ScanTarget{ProjectId: "p1", ProjectTypes: []string{"python"}, Directory: &directory, Generator: GeneratorCommand, Command: &CommandOptions{
+				Executable: "trivy",
+				Args:       []string{"fs", "--format", "cyclonedx", "--output", "{output}", "--tag", "{projectId}-{type}", "{dir}"},
+			}},
+			wantArgs: []string{"fs", "--format", "cyclonedx", "--output", "{output}", "--tag", "p1-python", "services/api"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			argsFile := installFakeGenerator(t, tt.executable, fakeSbom)
+			repoPath := t.TempDir()
+			if err := os.MkdirAll(filepath.Join(repoPath, directory), 0o755); err != nil {
+				t.Fatal(err)
+			}
+
+			sbom, err := NewDefaultGeneratorRegistry().AnalyzeProject(t.Context(), gittool.ClonedRepo{Path: repoPath}, &tt.target)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if sbom.Data != fakeSbom || sbom.ProjectId != "p1" || sbom.ProjectType != tt.target.ProjectType() {
+				t.Errorf("unexpected sbom: %+v", sbom)
+			}
+
+			dir, args := readArgs(t, argsFile)
+			if wantDir := filepath.Join(repoPath, tt.wantDir); dir != wantDir {
+				t.Errorf("expected to run in %s, got %s", wantDir, dir)
+			}
+			args, outputPath := replaceOutputPath(args)
+			if got, want := strings.Join(args, " "), strings.Join(tt.wantArgs, " "); got != want {
+				t.Errorf("expected args %q, got %q", want, got)
+			}
+			if strings.HasPrefix(outputPath, repoPath) {
+				t.Errorf("expected output file outside of the repo, got %s", outputPath)
+			}
+			if _, err := os.Stat(filepath.Dir(outputPath)); !os.IsNotExist(err) {
+				t.Errorf("expected output folder to be removed")
+			}
+		})
+	}
+}
+
+func TestGeneratorRegistry_AnalyzeProject_UniqueOutputPaths(t *testing.T) {
+	argsFile := installFakeGenerator(t, "cdxgen", fakeSbom)
+	target := &ScanTarget{ProjectId: "p1", ProjectTypes: []string{"java"}}
+	repo := gittool.ClonedRepo{Path: t.TempDir()}
+
+	var outputPaths []string
+	for range 2 {
+		if _, err := NewDefaultGeneratorRegistry().AnalyzeProject(t.Context(), repo, target); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		_, args := readArgs(t, argsFile)
+		_, outputPath := replaceOutputPath(args)
+		outputPaths = append(outputPaths, outputPath)
+		os.Remove(argsFile)
+	}
+
+	if outputPaths[0] == "" || outputPaths[0] == outputPaths[1] {
+		t.Errorf("expected unique output paths per run, got %v", outputPaths)
+	}
+}
+
+func TestGeneratorRegistry_AnalyzeProject_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		target  ScanTarget
+		wantErr string
+	}{
+		{name: "unknown generator", content: fakeSbom, target: ScanTarget{ProjectTypes: []string{"node"}, Generator: "bom-o-matic"}, wantErr: "unknown generator"},
+		{name: "command without executable", content: fakeSbom, target: ScanTarget{ProjectTypes: []string{"node"}, Generator: GeneratorCommand}, wantErr: "requires an executable"},
+		{name: "failing generator", target: ScanTarget{ProjectTypes: []string{"node"}, Generator: GeneratorSyft}, wantErr: "generator failed"},
+		{name: "no cyclonedx", content: `{"spdxVersion":"SPDX-2.3"}`, target: ScanTarget{ProjectTypes: []string{"node"}, Generator: GeneratorSyft}, wantErr: "invalid sbom"},
+		{name: "negative timeout", content: fakeSbom, target: ScanTarget{ProjectTypes: []string{"node"}, Generator: GeneratorSyft, Timeout: -time.Second}, wantErr: "timeout must not be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			installFakeGenerator(t, "syft", tt.content)
+
+			_, err := NewDefaultGeneratorRegistry().AnalyzeProject(t.Context(), gittool.ClonedRepo{Path: t.TempDir()}, &tt.target)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestGeneratorRegistry_AnalyzeProject_Timeout(t *testing.T) {
+	binDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(binDir, "syft"), []byte("#!/bin/sh\nexec sleep 10\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	target := &ScanTarget{ProjectId: "p1", ProjectTypes: []string{"node"}, Generator: GeneratorSyft, Timeout: 100 * time.Millisecond}
+	start := time.Now()
+	_, err := NewDefaultGeneratorRegistry().AnalyzeProject(t.Context(), gittool.ClonedRepo{Path: t.TempDir()}, target)
+	if err == nil || !strings.Contains(err.Error(), "syft timed out after 100ms") {
+		t.Errorf("expected timeout error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected syft to be killed, took %s", elapsed)
+	}
+}
+
+func TestScanTarget_Timeout(t *testing.T) {
+	tests := []struct {
+		name   string
+		target ScanTarget
+		want   time.Duration
+	}{
+		{name: "unlimited", target: ScanTarget{}, want: 0},
+		{name: "target timeout", target: ScanTarget{Generator: GeneratorSyft, Timeout: time.Minute}, want: time.Minute},
+		{name: "cdxgen overrides target", target: ScanTarget{Timeout: time.Minute, Cdxgen: &CdxgenOptions{Timeout: time.Second}}, want: time.Second},
+		{name: "cdxgen without timeout", target: ScanTarget{Timeout: time.Minute, Cdxgen: &CdxgenOptions{SpecVersion: "1.5"}}, want: time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.target.timeout(); got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+// replaceOutputPath replaces the path of the SBOM file within the args by {output} and returns it
+func replaceOutputPath(args []string) ([]string, string) {
+	replaced := make([]string, len(args))
+	outputPath := ""
+	for i, arg := range args {
+		if index := strings.Index(arg, string(filepath.Separator)); index >= 0 && strings.HasSuffix(arg, "sbom.json") {
+			outputPath = arg[index:]
+			arg = arg[:index] + "{output}"
+		}
+		replaced[i] = arg
+	}
+	return replaced, outputPath
+}
+
+func TestGeneratorRegistry_Register(t *testing.T) {
+	registry := NewGeneratorRegistry()
+	registry.Register("custom", SyftGenerator{})
+
+	if _, err := registry.Get("custom"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := registry.Get(GeneratorCdxgen); err == nil {
+		t.Error("expected error for generator missing in registry")
+	}
+	if names := registry.Names(); len(names) != 1 || names[0] != "custom" {
+		t.Errorf("unexpected names: %v", names)
+	}
+}
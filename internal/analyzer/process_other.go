@@ -0,0 +1,14 @@
+//go:build !unix
+
+package analyzer
+
+import (
+	"os/exec"
+	"time"
+)
+
+// killProcessGroupOnCancel only kills the generator itself, once the context of the command is done.
+// Process groups are not supported on this platform.
+func killProcessGroupOnCancel(cmd *exec.Cmd) {
+	cmd.WaitDelay = 10 * time.Second
+}
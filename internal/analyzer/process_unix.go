@@ -0,0 +1,21 @@
+//go:build unix
+
+package analyzer
+
+import (
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// killProcessGroupOnCancel starts the generator in its own process group and kills the whole group,
+// once the context of the command is done. Generators like cdxgen spawn npm, mvn or gradle, which
+// would otherwise keep running.
+func killProcessGroupOnCancel(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	// Children may still hold the output pipes, the output is not awaited longer than this
+	cmd.WaitDelay = 10 * time.Second
+}
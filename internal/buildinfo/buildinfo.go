@@ -0,0 +1,5 @@
+// Package buildinfo holds the version of central-cyclone. It is set at build time with
+// -ldflags "-X central-cyclone/internal/buildinfo.Version=v1.2.3".
+package buildinfo
+
+var Version = "dev"
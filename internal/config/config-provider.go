@@ -2,14 +2,22 @@ package config
 
 import (
 	"central-cyclone/internal/analyzer"
+	"central-cyclone/internal/gittool"
 	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
 )
 
+const defaultGitOpsStateFileName = "gitops-state.json"
+
 type ConfigProvider struct {
 	settings           *Settings
 	applicationRepoMap map[string]string
 	applicationMap     map[string]*Application
 	projectMap         map[string]*Project
+	revisionRulesMap   map[string][]gittool.RevisionRule
 }
 
 func NewConfigProvider(settings *Settings) (*ConfigProvider, error) {
@@ -18,6 +26,7 @@ func NewConfigProvider(settings *Settings) (*ConfigProvider, error) {
 		applicationRepoMap: make(map[string]string),
 		applicationMap:     make(map[string]*Application),
 		projectMap:         make(map[string]*Project),
+		revisionRulesMap:   make(map[string][]gittool.RevisionRule),
 	}
 
 	// Validate and build lookup maps
@@ -42,8 +51,21 @@ func (c *ConfigProvider) validateAndBuildMaps() error {
 	for i := range c.settings.Applications {
 		app := &c.settings.Applications[i]
 		c.applicationMap[app.Name] = app
+		if err := validateScanTarget(app.scanTarget(nil)); err != nil {
+			return fmt.Errorf("application '%s': %w", app.Name, err)
+		}
+		for k, ruleConfig := range app.RevisionRules {
+			rule, err := gittool.NewRevisionRule(ruleConfig.Match, ruleConfig.Revision, ruleConfig.Branch)
+			if err != nil {
+				return fmt.Errorf("application '%s' has an invalid revision rule at index %d: %w", app.Name, k, err)
+			}
+			c.revisionRulesMap[app.Name] = append(c.revisionRulesMap[app.Name], rule)
+		}
 		for j := range app.Projects {
 			project := &app.Projects[j]
+			if err := app.scanTarget(project).ValidateProject(); err != nil {
+				return fmt.Errorf("application '%s' project for environment '%s': %w", app.Name, project.Environment, err)
+			}
 			// Use format: appName:environment as key
 			key := fmt.Sprintf("%s:%s", app.Name, project.Environment)
 			c.projectMap[key] = project
@@ -67,6 +89,16 @@ func (c *ConfigProvider) validateAndBuildMaps() error {
 
 			// Check that every environment has a matching Project
 			for _, versionIdentifier := range gitOpsApp.VersionIdentifiers {
+				if err := validateVersionIdentifier(versionIdentifier); err != nil {
+					return fmt.Errorf("GitOps application '%s' with environment '%s': %w", appName, versionIdentifier.Environment, err)
+				}
+				// Environments of templated identifiers are discovered and paired with the projects on initialization of the syncer
+				if versionIdentifier.IsTemplated() {
+					if len(c.applicationMap[appName].Projects) == 0 {
+						return fmt.Errorf("GitOps application '%s' with templated filepath '%s' has no Projects in Application '%s'", appName, versionIdentifier.Filepath, appName)
+					}
+					continue
+				}
 				key := fmt.Sprintf("%s:%s", appName, versionIdentifier.Environment)
 				if _, exists := c.projectMap[key]; !exists {
 					return fmt.Errorf(
@@ -81,6 +113,73 @@ func (c *ConfigProvider) validateAndBuildMaps() error {
 	return nil
 }
 
+func validateVersionIdentifier(versionIdentifier VersionIdentifier) error {
+	if versionIdentifier.IsTemplated() {
+		if versionIdentifier.Environment != "" {
+			return fmt.Errorf("version identifier with templated filepath '%s' must not set an environment", versionIdentifier.Filepath)
+		}
+		if strings.Count(versionIdentifier.Filepath, EnvironmentPlaceholder) != 1 {
+			return fmt.Errorf("templated filepath '%s' must contain %s exactly once", versionIdentifier.Filepath, EnvironmentPlaceholder)
+		}
+	} else if versionIdentifier.Environment == "" {
+		return fmt.Errorf("version identifier with filepath '%s' requires an environment or %s in the filepath", versionIdentifier.Filepath, EnvironmentPlaceholder)
+	}
+
+	switch versionIdentifier.GetKind() {
+	case VersionIdentifierKindYq:
+		if versionIdentifier.YamlPath == "" {
+			return fmt.Errorf("version identifier of kind '%s' requires a yamlPath", VersionIdentifierKindYq)
+		}
+	case VersionIdentifierKindKustomizeImage:
+		if versionIdentifier.ImageName == "" {
+			return fmt.Errorf("version identifier of kind '%s' requires an imageName", VersionIdentifierKindKustomizeImage)
+		}
+	case VersionIdentifierKindArgoApplication, VersionIdentifierKindHelmRelease:
+	default:
+		return fmt.Errorf("unknown version identifier kind '%s'", versionIdentifier.Kind)
+	}
+
+	if image := versionIdentifier.Image; image != nil {
+		if image.Use != "" && image.Use != "tag" && image.Use != "digest" {
+			return fmt.Errorf("unknown image part '%s', expected 'tag' or 'digest'", image.Use)
+		}
+		if image.RevisionPattern != "" {
+			pattern, err := regexp.Compile(image.RevisionPattern)
+			if err != nil {
+				return fmt.Errorf("invalid image revision pattern '%s': %w", image.RevisionPattern, err)
+			}
+			if pattern.NumSubexp() < 1 {
+				return fmt.Errorf("image revision pattern '%s' requires a capture group", image.RevisionPattern)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateRepositories checks the projects, generators and generator options of all targets of the repositories to analyze
+func ValidateRepositories(settings *Settings) error {
+	if settings == nil {
+		return nil
+	}
+	for _, repo := range settings.Repositories {
+		for _, target := range repo.Targets {
+			scanTarget := target.ScanTarget()
+			if err := validateScanTarget(scanTarget); err != nil {
+				return fmt.Errorf("repository '%s' target '%s': %w", repo.Url, scanTarget.Project(), err)
+			}
+			if err := scanTarget.ValidateProject(); err != nil {
+				return fmt.Errorf("repository '%s' target '%s': %w", repo.Url, scanTarget.Project(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateScanTarget checks that the generator is registered and its options are valid
+func validateScanTarget(target *analyzer.ScanTarget) error {
+	return analyzer.NewDefaultGeneratorRegistry().Validate(target)
+}
+
 func (c *ConfigProvider) GetApplicationRepo(applicationName string) (string, error) {
 	repoUrl, exists := c.applicationRepoMap[applicationName]
 	if !exists {
@@ -89,6 +188,11 @@ func (c *ConfigProvider) GetApplicationRepo(applicationName string) (string, err
 	return repoUrl, nil
 }
 
+// GetRevisionRules returns the compiled revision rules of the application, in the configured order
+func (c *ConfigProvider) GetRevisionRules(applicationName string) []gittool.RevisionRule {
+	return c.revisionRulesMap[applicationName]
+}
+
 func (c *ConfigProvider) getApplication(applicationName string) *Application {
 	return c.applicationMap[applicationName]
 }
@@ -105,11 +209,28 @@ func (c *ConfigProvider) GetScanTargetForApplication(applicationName, env string
 		return nil, fmt.Errorf("project config not found for application: %s and environment: %s", applicationName, env)
 	}
 
-	return &analyzer.ScanTarget{
-		ProjectId:   *project.ProjectId,
-		ProjectType: applicationConfig.Type,
-		Directory:   applicationConfig.RepoPath,
-	}, nil
+	return applicationConfig.scanTarget(project), nil
+}
+
+// ResolveEnvironment pairs an environment discovered by a templated version identifier with the environment
+// of a Project of the application. An exact match is preferred over a case-insensitive one.
+func (c *ConfigProvider) ResolveEnvironment(applicationName, discoveredEnvironment string) (string, bool) {
+	applicationConfig := c.getApplication(applicationName)
+	if applicationConfig == nil {
+		return "", false
+	}
+
+	for _, project := range applicationConfig.Projects {
+		if project.Environment == discoveredEnvironment {
+			return project.Environment, true
+		}
+	}
+	for _, project := range applicationConfig.Projects {
+		if strings.EqualFold(project.Environment, discoveredEnvironment) {
+			return project.Environment, true
+		}
+	}
+	return "", false
 }
 
 // GetGitOpsRefreshInterval returns the configured refresh interval in minutes.
@@ -120,3 +241,40 @@ func (c *ConfigProvider) GetGitOpsRefreshInterval() int {
 	}
 	return *c.settings.GitOps.RefreshInterval
 }
+
+// GetGitOpsMaxConcurrency returns the configured number of app changes handled in parallel.
+// If not configured, it returns the default value of 1.
+func (c *ConfigProvider) GetGitOpsMaxConcurrency() int {
+	if c.settings.GitOps.MaxConcurrency == nil || *c.settings.GitOps.MaxConcurrency < 1 {
+		return 1
+	}
+	return *c.settings.GitOps.MaxConcurrency
+}
+
+// GetGitOpsRetry returns the configured retry settings of failed app changes.
+// Unset values are returned as zero and replaced by the defaults of the syncer.
+func (c *ConfigProvider) GetGitOpsRetry() (maxAttempts int, initialBackoff time.Duration, maxBackoff time.Duration) {
+	retry := c.settings.GitOps.Retry
+	if retry == nil {
+		return 0, 0, 0
+	}
+	if retry.MaxAttempts != nil {
+		maxAttempts = *retry.MaxAttempts
+	}
+	if retry.InitialBackoff != nil {
+		initialBackoff = time.Duration(*retry.InitialBackoff) * time.Minute
+	}
+	if retry.MaxBackoff != nil {
+		maxBackoff = time.Duration(*retry.MaxBackoff) * time.Minute
+	}
+	return maxAttempts, initialBackoff, maxBackoff
+}
+
+// GetGitOpsStateFile returns the configured path of the GitOps state file.
+// If not configured, it returns gitops-state.json within the given workfolder.
+func (c *ConfigProvider) GetGitOpsStateFile(workFolderPath string) string {
+	if c.settings.GitOps.StateFile == nil {
+		return filepath.Join(workFolderPath, defaultGitOpsStateFileName)
+	}
+	return *c.settings.GitOps.StateFile
+}
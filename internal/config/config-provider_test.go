@@ -1,7 +1,9 @@
 package config
 
 import (
+	"slices"
 	"testing"
+	"time"
 )
 
 func TestNewConfigProvider_Validation_MissingApplication(t *testing.T) {
@@ -33,7 +35,7 @@ func TestNewConfigProvider_Validation_MissingApplication(t *testing.T) {
 func TestNewConfigProvider_Validation_MissingApplicationRepo(t *testing.T) {
 	settings := &Settings{
 		Applications: []Application{
-			{Name: "test-app", Type: "go"},
+			{Name: "test-app", Type: ProjectTypes{"go"}},
 		},
 		GitOpsRepos: []GitOpsRepo{
 			{
@@ -65,7 +67,7 @@ func TestNewConfigProvider_Validation_MissingProject(t *testing.T) {
 		Applications: []Application{
 			{
 				Name: "test-app",
-				Type: "go",
+				Type: ProjectTypes{"go"},
 				Projects: []Project{
 					{Name: "test-app", Environment: "staging", ProjectId: &projectId},
 				},
@@ -105,7 +107,7 @@ func TestNewConfigProvider_Validation_Success(t *testing.T) {
 		Applications: []Application{
 			{
 				Name: "test-app",
-				Type: "go",
+				Type: ProjectTypes{"go"},
 				Projects: []Project{
 					{Name: "test-app", Environment: "prod", ProjectId: &projectId},
 				},
@@ -145,7 +147,7 @@ func TestNewConfigProvider_Validation_MultipleApps(t *testing.T) {
 		Applications: []Application{
 			{
 				Name: "app1",
-				Type: "go",
+				Type: ProjectTypes{"go"},
 				Projects: []Project{
 					{Name: "app1", Environment: "prod", ProjectId: &projectId1},
 					{Name: "app1", Environment: "staging", ProjectId: &projectId2},
@@ -153,7 +155,7 @@ func TestNewConfigProvider_Validation_MultipleApps(t *testing.T) {
 			},
 			{
 				Name: "app2",
-				Type: "node",
+				Type: ProjectTypes{"node"},
 				Projects: []Project{
 					{Name: "app2", Environment: "prod", ProjectId: &projectId1},
 				},
@@ -192,3 +194,300 @@ func TestNewConfigProvider_Validation_MultipleApps(t *testing.T) {
 		t.Fatal("expected non-nil provider")
 	}
 }
+
+func TestNewConfigProvider_Validation_VersionIdentifierKinds(t *testing.T) {
+	tests := []struct {
+		name              string
+		versionIdentifier VersionIdentifier
+		wantError         bool
+	}{
+		{name: "yq without yamlPath", versionIdentifier: VersionIdentifier{Environment: "prod", Filepath: "values.yaml"}, wantError: true},
+		{name: "kustomizeImage", versionIdentifier: VersionIdentifier{Environment: "prod", Filepath: "kustomization.yaml", Kind: VersionIdentifierKindKustomizeImage, ImageName: "ghcr.io/org/app"}},
+		{name: "kustomizeImage without imageName", versionIdentifier: VersionIdentifier{Environment: "prod", Filepath: "kustomization.yaml", Kind: VersionIdentifierKindKustomizeImage}, wantError: true},
+		{name: "argoApplication", versionIdentifier: VersionIdentifier{Environment: "prod", Filepath: "app.yaml", Kind: VersionIdentifierKindArgoApplication}},
+		{name: "helmRelease", versionIdentifier: VersionIdentifier{Environment: "prod", Filepath: "release.yaml", Kind: VersionIdentifierKindHelmRelease, ResourceName: "app"}},
+		{name: "unknown kind", versionIdentifier: VersionIdentifier{Environment: "prod", Filepath: "app.yaml", Kind: "jsonnet"}, wantError: true},
+		{name: "image", versionIdentifier: VersionIdentifier{Environment: "prod", Filepath: "deployment.yaml", YamlPath: ".image", Image: &ImageVersion{StripPrefix: "v", RevisionPattern: `^(\d+\.\d+\.\d+)`}}},
+		{name: "image with unknown part", versionIdentifier: VersionIdentifier{Environment: "prod", Filepath: "deployment.yaml", YamlPath: ".image", Image: &ImageVersion{Use: "label"}}, wantError: true},
+		{name: "image with invalid pattern", versionIdentifier: VersionIdentifier{Environment: "prod", Filepath: "deployment.yaml", YamlPath: ".image", Image: &ImageVersion{RevisionPattern: "^v.*$"}}, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projectId := "test-project-id"
+			settings := &Settings{
+				Applications: []Application{
+					{Name: "test-app", Type: ProjectTypes{"go"}, Projects: []Project{{Name: "test-app", Environment: "prod", ProjectId: &projectId}}},
+				},
+				ApplicationRepos: []ApplicationRepo{
+					{Applications: []string{"test-app"}, RepoUrl: "https://github.com/test/repo.git"},
+				},
+				GitOpsRepos: []GitOpsRepo{
+					{
+						Url: "https://github.com/test/gitops.git",
+						GitOpsApplications: []GitOpsApplication{
+							{ApplicationName: "test-app", VersionIdentifiers: []VersionIdentifier{tt.versionIdentifier}},
+						},
+					},
+				},
+			}
+
+			_, err := NewConfigProvider(settings)
+			if tt.wantError && err == nil {
+				t.Error("expected error, got nil")
+			}
+			if !tt.wantError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestNewConfigProvider_Validation_TemplatedVersionIdentifiers(t *testing.T) {
+	tests := []struct {
+		name              string
+		versionIdentifier VersionIdentifier
+		wantError         bool
+	}{
+		{name: "templated", versionIdentifier: VersionIdentifier{Filepath: "apps/test/{env}/values.yaml", YamlPath: ".version"}},
+		{name: "templated with environment", versionIdentifier: VersionIdentifier{Environment: "prod", Filepath: "apps/test/{env}/values.yaml", YamlPath: ".version"}, wantError: true},
+		{name: "placeholder twice", versionIdentifier: VersionIdentifier{Filepath: "apps/{env}/{env}.yaml", YamlPath: ".version"}, wantError: true},
+		{name: "neither environment nor placeholder", versionIdentifier: VersionIdentifier{Filepath: "apps/test/values.yaml", YamlPath: ".version"}, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projectId := "test-project-id"
+			settings := &Settings{
+				Applications: []Application{
+					{Name: "test-app", Type: ProjectTypes{"go"}, Projects: []Project{{Name: "test-app", Environment: "prod", ProjectId: &projectId}}},
+				},
+				ApplicationRepos: []ApplicationRepo{
+					{Applications: []string{"test-app"}, RepoUrl: "https://github.com/test/repo.git"},
+				},
+				GitOpsRepos: []GitOpsRepo{
+					{
+						Url: "https://github.com/test/gitops.git",
+						GitOpsApplications: []GitOpsApplication{
+							{ApplicationName: "test-app", VersionIdentifiers: []VersionIdentifier{tt.versionIdentifier}},
+						},
+					},
+				},
+			}
+
+			_, err := NewConfigProvider(settings)
+			if tt.wantError && err == nil {
+				t.Error("expected error, got nil")
+			}
+			if !tt.wantError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestConfigProvider_ResolveEnvironment(t *testing.T) {
+	projectId := "test-project-id"
+	provider, err := NewConfigProvider(&Settings{
+		Applications: []Application{
+			{Name: "test-app", Type: ProjectTypes{"go"}, Projects: []Project{
+				{Name: "test-app (Prod)", Environment: "Prod", ProjectId: &projectId},
+				{Name: "test-app (prod)", Environment: "prod", ProjectId: &projectId},
+				{Name: "test-app (Dev)", Environment: "Dev", ProjectId: &projectId},
+			}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		discovered string
+		want       string
+		wantOk     bool
+	}{
+		{discovered: "prod", want: "prod", wantOk: true},
+		{discovered: "dev", want: "Dev", wantOk: true},
+		{discovered: "base", wantOk: false},
+	}
+	for _, tt := range tests {
+		got, ok := provider.ResolveEnvironment("test-app", tt.discovered)
+		if got != tt.want || ok != tt.wantOk {
+			t.Errorf("ResolveEnvironment(%q) = %q, %v, want %q, %v", tt.discovered, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
+
+func TestNewConfigProvider_RevisionRules(t *testing.T) {
+	projectId := "test-project-id"
+	settings := &Settings{
+		Applications: []Application{
+			{
+				Name:     "test-app",
+				Type:     ProjectTypes{"go"},
+				Projects: []Project{{Name: "test-app", Environment: "prod", ProjectId: &projectId}},
+				RevisionRules: []RevisionRule{
+					{Match: `^\d+\.\d+\.\d+$`, Revision: "v$0"},
+					{Branch: "main"},
+				},
+			},
+		},
+	}
+
+	provider, err := NewConfigProvider(settings)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rules := provider.GetRevisionRules("test-app")
+	if len(rules) != 2 || rules[0].Revision != "v$0" || rules[1].Branch != "main" {
+		t.Errorf("expected rules in configured order, got %v", rules)
+	}
+
+	settings.Applications[0].RevisionRules = append(settings.Applications[0].RevisionRules, RevisionRule{Match: "("})
+	if _, err := NewConfigProvider(settings); err == nil {
+		t.Error("expected error for invalid revision rule")
+	}
+}
+
+func TestNewConfigProvider_Validation_Generators(t *testing.T) {
+	timeout := 30
+	negativeTimeout := -1
+	tests := []struct {
+		name      string
+		generator string
+		command   *CommandGenerator
+		cdxgen    *CdxgenConfig
+		timeout   *int
+		wantError bool
+	}{
+		{name: "default"},
+		{name: "cdxgen options", cdxgen: &CdxgenConfig{Args: []string{"--required-only"}, Env: map[string]string{"FETCH_LICENSE": "true"}, SpecVersion: "1.5", Timeout: &timeout}},
+		{name: "cdxgen options with unsupported spec version", cdxgen: &CdxgenConfig{SpecVersion: "0.9"}, wantError: true},
+		{name: "cdxgen options with managed flag", cdxgen: &CdxgenConfig{Args: []string{"-o", "out.json"}}, wantError: true},
+		{name: "cdxgen options for syft", generator: "syft", cdxgen: &CdxgenConfig{}, wantError: true},
+		{name: "syft", generator: "syft"},
+		{name: "syft with timeout", generator: "syft", timeout: &timeout},
+		{name: "negative timeout", generator: "syft", timeout: &negativeTimeout, wantError: true},
+		{name: "command", generator: "command", command: &CommandGenerator{Executable: "trivy", Args: []string{"fs", "{dir}"}}},
+		{name: "command without executable", generator: "command", command: &CommandGenerator{}, wantError: true},
+		{name: "command without command", generator: "command", wantError: true},
+		{name: "unknown generator", generator: "bom-o-matic", wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := &Settings{
+				Applications: []Application{{Name: "test-app", Type: ProjectTypes{"go"}, Generator: tt.generator, Command: tt.command, Cdxgen: tt.cdxgen, Timeout: tt.timeout}},
+			}
+			_, err := NewConfigProvider(settings)
+			if tt.wantError != (err != nil) {
+				t.Errorf("expected error %v, got %v", tt.wantError, err)
+			}
+
+			repoSettings := &Settings{
+				Repositories: []Repo{{Url: "https://github.com/test/repo.git", Targets: []RepoTarget{{ProjectId: "id", Type: ProjectTypes{"go"}, Generator: tt.generator, Command: tt.command, Cdxgen: tt.cdxgen, Timeout: tt.timeout}}}},
+			}
+			err = ValidateRepositories(repoSettings)
+			if tt.wantError != (err != nil) {
+				t.Errorf("expected error %v for repository target, got %v", tt.wantError, err)
+			}
+		})
+	}
+}
+
+func TestRepoTarget_ScanTarget_Timeout(t *testing.T) {
+	timeout := 20
+	cdxgenTimeout := 45
+	target := RepoTarget{ProjectId: "id", Type: ProjectTypes{"go"}, Timeout: &timeout, Cdxgen: &CdxgenConfig{Timeout: &cdxgenTimeout}}
+
+	scanTarget := target.ScanTarget()
+	if scanTarget.Timeout != 20*time.Minute {
+		t.Errorf("expected target timeout of 20m, got %s", scanTarget.Timeout)
+	}
+	if scanTarget.Cdxgen.Timeout != 45*time.Minute {
+		t.Errorf("expected cdxgen timeout of 45m, got %s", scanTarget.Cdxgen.Timeout)
+	}
+}
+
+func TestNewConfigProvider_Validation_Projects(t *testing.T) {
+	projectId := "test-project-id"
+	tests := []struct {
+		name      string
+		project   Project
+		wantError bool
+	}{
+		{name: "project id", project: Project{Environment: "prod", ProjectId: &projectId}},
+		{name: "name and environment", project: Project{Name: "test-app", Environment: "prod"}},
+		{name: "no id and no name", project: Project{Environment: "prod"}, wantError: true},
+		{name: "parent with autoCreate", project: Project{Name: "test-app", Environment: "prod", AutoCreate: true, Parent: &ParentProject{Name: "platform"}}},
+		{name: "parent without autoCreate", project: Project{Name: "test-app", Environment: "prod", Parent: &ParentProject{Name: "platform"}}, wantError: true},
+		{name: "parent without uuid and name", project: Project{Name: "test-app", Environment: "prod", AutoCreate: true, Parent: &ParentProject{Version: "1"}}, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := &Settings{
+				Applications: []Application{{Name: "test-app", Type: ProjectTypes{"go"}, Projects: []Project{tt.project}}},
+			}
+			_, err := NewConfigProvider(settings)
+			if tt.wantError != (err != nil) {
+				t.Errorf("expected error %v, got %v", tt.wantError, err)
+			}
+		})
+	}
+}
+
+func TestConfigProvider_GetScanTargetForApplication_ByNameAndVersion(t *testing.T) {
+	settings := &Settings{
+		Applications: []Application{{
+			Name: "test-app",
+			Type: ProjectTypes{"go"},
+			Projects: []Project{{
+				Name:        "test-app",
+				Environment: "prod",
+				AutoCreate:  true,
+				Parent:      &ParentProject{Uuid: "parent-uuid"},
+				Tags:        []string{"team-a"},
+			}},
+		}},
+	}
+	provider, err := NewConfigProvider(settings)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	target, err := provider.GetScanTargetForApplication("test-app", "prod")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if target.ProjectId != "" || target.ProjectName != "test-app" || target.ProjectVersion != "prod" {
+		t.Errorf("unexpected project %q %q@%q", target.ProjectId, target.ProjectName, target.ProjectVersion)
+	}
+	if !target.AutoCreate || target.Parent == nil || target.Parent.Uuid != "parent-uuid" || !slices.Equal(target.Tags, []string{"team-a"}) {
+		t.Errorf("unexpected upload options %+v", target)
+	}
+}
+
+func TestValidateRepositories_Projects(t *testing.T) {
+	tests := []struct {
+		name      string
+		target    RepoTarget
+		wantError bool
+	}{
+		{name: "project id", target: RepoTarget{ProjectId: "id"}},
+		{name: "name and version", target: RepoTarget{ProjectName: "app", ProjectVersion: "main", AutoCreate: true, Tags: []string{"team-a"}}},
+		{name: "name without version", target: RepoTarget{ProjectName: "app"}, wantError: true},
+		{name: "neither id nor name", target: RepoTarget{}, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.target.Type = ProjectTypes{"go"}
+			settings := &Settings{Repositories: []Repo{{Url: "https://github.com/test/repo.git", Targets: []RepoTarget{tt.target}}}}
+			err := ValidateRepositories(settings)
+			if tt.wantError != (err != nil) {
+				t.Errorf("expected error %v, got %v", tt.wantError, err)
+			}
+		})
+	}
+}
@@ -1,9 +1,14 @@
 package config
 
 import (
+	"central-cyclone/internal/analyzer"
+	"central-cyclone/internal/models"
 	"encoding/json"
+	"fmt"
 	"log/slog"
 	"os"
+	"strings"
+	"time"
 )
 
 type Settings struct {
@@ -21,9 +26,59 @@ type Repo struct {
 }
 
 type RepoTarget struct {
-	ProjectId string  `json:"projectId"`
-	Type      string  `json:"type"`
-	Directory *string `json:"directory"`
+	ProjectId      string            `json:"projectId,omitempty"`      // Optional if projectName and projectVersion are set
+	ProjectName    string            `json:"projectName,omitempty"`    // Identifies the project together with the version, if no id is set
+	ProjectVersion string            `json:"projectVersion,omitempty"` // Identifies the project together with the name, if no id is set
+	AutoCreate     bool              `json:"autoCreate,omitempty"`     // Optional, creates a missing project on upload
+	Parent         *ParentProject    `json:"parent,omitempty"`         // Optional parent of a created project, requires autoCreate
+	Tags           []string          `json:"tags,omitempty"`           // Optional tags of a created project
+	Type           ProjectTypes      `json:"type"`
+	Directory      *string           `json:"directory"`
+	Generator      string            `json:"generator,omitempty"` // Optional SBOM generator, defaults to cdxgen
+	Command        *CommandGenerator `json:"command,omitempty"`   // Required for the command generator
+	Cdxgen         *CdxgenConfig     `json:"cdxgen,omitempty"`    // Optional options of the cdxgen generator
+	Timeout        *int              `json:"timeout,omitempty"`   // Optional timeout of a generator run in minutes, unlimited by default
+}
+
+// ParentProject identifies an existing DependencyTrack project either by its uuid or by name and optional version
+type ParentProject struct {
+	Uuid    string `json:"uuid,omitempty"`
+	Name    string `json:"name,omitempty"`
+	Version string `json:"version,omitempty"`
+}
+
+// ProjectTypes are the project types of a target, merged by cdxgen into a single SBOM.
+// A single type can be configured as string, multiple types as list, e.g. ["java", "js"].
+type ProjectTypes []string
+
+func (p *ProjectTypes) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*p = nil
+		if single != "" {
+			*p = ProjectTypes{single}
+		}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("type must be a string or a list of strings: %w", err)
+	}
+	*p = list
+	return nil
+}
+
+// String returns the project types separated by commas
+func (p ProjectTypes) String() string {
+	return strings.Join(p, ",")
+}
+
+// CommandGenerator runs an arbitrary executable, which writes a CycloneDX JSON SBOM.
+// The args may contain the placeholders {output}, {dir}, {repo}, {type} and {projectId}.
+type CommandGenerator struct {
+	Executable string   `json:"executable"`
+	Args       []string `json:"args,omitempty"`
 }
 
 type DependencyTrackConfig struct {
@@ -31,21 +86,48 @@ type DependencyTrackConfig struct {
 }
 
 type GitOpsConfig struct {
-	RefreshInterval *int `json:"refreshInterval"` // Optional refresh interval in minutes, defaults to 10
+	RefreshInterval *int               `json:"refreshInterval"` // Optional refresh interval in minutes, defaults to 10
+	StateFile       *string            `json:"stateFile"`       // Optional path of the state file, defaults to gitops-state.json within the workfolder
+	MaxConcurrency  *int               `json:"maxConcurrency"`  // Optional number of app changes handled in parallel, defaults to 1
+	Retry           *GitOpsRetryConfig `json:"retry"`
+}
+
+type GitOpsRetryConfig struct {
+	MaxAttempts    *int `json:"maxAttempts"`    // Optional attempts per version before it is parked as failed, defaults to 5
+	InitialBackoff *int `json:"initialBackoff"` // Optional backoff after the first failed attempt in minutes, defaults to 10
+	MaxBackoff     *int `json:"maxBackoff"`     // Optional upper limit of the doubling backoff in minutes, defaults to 360
 }
 
 type Application struct {
-	Name     string    `json:"name"`
-	Type     string    `json:"type"`
-	RepoPath *string   `json:"repoPath", omitempty`
-	Projects []Project `json:"projects"`
+	Name     string       `json:"name"`
+	Type     ProjectTypes `json:"type"`
+	RepoPath *string      `json:"repoPath,omitempty"`
+	Projects []Project    `json:"projects"`
+	// Optional SBOM generator, defaults to cdxgen. The command generator requires a command.
+	Generator string            `json:"generator,omitempty"`
+	Command   *CommandGenerator `json:"command,omitempty"`
+	Cdxgen    *CdxgenConfig     `json:"cdxgen,omitempty"`
+	Timeout   *int              `json:"timeout,omitempty"` // Optional timeout of a generator run in minutes, unlimited by default
+	// Optional rules mapping deployed versions to git revisions, tried in order before the built-in resolution
+	RevisionRules []RevisionRule `json:"revisionRules,omitempty"`
+}
+
+type RevisionRule struct {
+	Match    string `json:"match,omitempty"`    // Optional regex, matches every version if empty
+	Revision string `json:"revision,omitempty"` // Revision template with the captures of match, e.g. v$0 or release/${1}
+	Branch   string `json:"branch,omitempty"`   // Branch template whose head is used, instead of a revision template
 }
 
+// Project is the DependencyTrack project of an application environment. Without a projectId it is identified
+// by its name and the environment as version, as created by the project sync.
 type Project struct {
-	Name        string  `json:"name"`
-	Environment string  `json:"environment"`
-	IsLatest    bool    `json:"isLatest"`
-	ProjectId   *string `json:"projectId"`
+	Name        string         `json:"name"`
+	Environment string         `json:"environment"`
+	IsLatest    bool           `json:"isLatest"`
+	ProjectId   *string        `json:"projectId"`
+	AutoCreate  bool           `json:"autoCreate,omitempty"` // Optional, creates a missing project on upload
+	Parent      *ParentProject `json:"parent,omitempty"`     // Optional parent of a created project, requires autoCreate
+	Tags        []string       `json:"tags,omitempty"`       // Optional tags of a created project
 }
 
 type GitOpsRepo struct {
@@ -58,10 +140,125 @@ type GitOpsApplication struct {
 	VersionIdentifiers []VersionIdentifier `json:"versionIdentifiers"`
 }
 
+// Kinds of version identifiers. The yq kind is used, if no kind is configured.
+const (
+	VersionIdentifierKindYq              = "yq"
+	VersionIdentifierKindArgoApplication = "argoApplication"
+	VersionIdentifierKindKustomizeImage  = "kustomizeImage"
+	VersionIdentifierKindHelmRelease     = "helmRelease"
+)
+
 type VersionIdentifier struct {
-	Environment string `json:"environment"`
-	Filepath    string `json:"filepath"`
-	YamlPath    string `json:"yamlPath"`
+	Environment  string        `json:"environment,omitempty"` // Required, unless the filepath contains the EnvironmentPlaceholder
+	Filepath     string        `json:"filepath"`
+	Kind         string        `json:"kind,omitempty"`         // Optional, defaults to yq
+	YamlPath     string        `json:"yamlPath,omitempty"`     // Required for the yq kind
+	ImageName    string        `json:"imageName,omitempty"`    // Required for the kustomizeImage kind
+	ResourceName string        `json:"resourceName,omitempty"` // Optional for the argoApplication and helmRelease kinds, selects the resource by metadata.name
+	Image        *ImageVersion `json:"image,omitempty"`        // Optional, treats the extracted value as image reference
+}
+
+// ImageVersion maps a full image reference like registry/app:1.4.2@sha256:... to the git revision of the application
+type ImageVersion struct {
+	Use             string `json:"use,omitempty"`             // Optional part of the reference, tag or digest, defaults to tag
+	StripPrefix     string `json:"stripPrefix,omitempty"`     // Optional prefix removed from the tag or digest
+	RevisionPattern string `json:"revisionPattern,omitempty"` // Optional regex, whose first capture group is used as revision
+}
+
+// EnvironmentPlaceholder can be used in the filepath of a version identifier instead of an explicit environment.
+// The environments are then discovered from the files matching the filepath, e.g. apps/basket/{env}/values.yaml.
+const EnvironmentPlaceholder = "{env}"
+
+// IsTemplated reports whether the filepath contains the EnvironmentPlaceholder
+func (v VersionIdentifier) IsTemplated() bool {
+	return strings.Contains(v.Filepath, EnvironmentPlaceholder)
+}
+
+// GetKind returns the configured kind, defaulting to yq
+func (v VersionIdentifier) GetKind() string {
+	if v.Kind == "" {
+		return VersionIdentifierKindYq
+	}
+	return v.Kind
+}
+
+// CdxgenConfig is passed through to cdxgen
+type CdxgenConfig struct {
+	Args        []string          `json:"args,omitempty"`        // Optional additional args, e.g. --required-only
+	Env         map[string]string `json:"env,omitempty"`         // Optional environment variables, e.g. FETCH_LICENSE
+	SpecVersion string            `json:"specVersion,omitempty"` // Optional CycloneDX spec version, defaults to 1.6
+	Timeout     *int              `json:"timeout,omitempty"`     // Optional timeout of a cdxgen run in minutes, unlimited by default
+}
+
+// ScanTarget returns the target to analyze with the configured generator
+func (t RepoTarget) ScanTarget() *analyzer.ScanTarget {
+	return &analyzer.ScanTarget{
+		ProjectId:      t.ProjectId,
+		ProjectName:    t.ProjectName,
+		ProjectVersion: t.ProjectVersion,
+		ProjectTypes:   t.Type,
+		Directory:      t.Directory,
+		Generator:      t.Generator,
+		Command:        t.Command.options(),
+		Cdxgen:         t.Cdxgen.options(),
+		Timeout:        minutes(t.Timeout),
+		AutoCreate:     t.AutoCreate,
+		Parent:         t.Parent.parent(),
+		Tags:           t.Tags,
+	}
+}
+
+// scanTarget returns the target to analyze for the project of the application. Without a project,
+// the target only carries the generator options.
+func (a *Application) scanTarget(project *Project) *analyzer.ScanTarget {
+	target := &analyzer.ScanTarget{
+		ProjectTypes: a.Type,
+		Directory:    a.RepoPath,
+		Generator:    a.Generator,
+		Command:      a.Command.options(),
+		Cdxgen:       a.Cdxgen.options(),
+		Timeout:      minutes(a.Timeout),
+	}
+	if project != nil {
+		if project.ProjectId != nil {
+			target.ProjectId = *project.ProjectId
+		}
+		target.ProjectName = project.Name
+		target.ProjectVersion = project.Environment
+		target.AutoCreate = project.AutoCreate
+		target.Parent = project.Parent.parent()
+		target.Tags = project.Tags
+	}
+	return target
+}
+
+func (p *ParentProject) parent() *models.ProjectParent {
+	if p == nil {
+		return nil
+	}
+	return &models.ProjectParent{Uuid: p.Uuid, Name: p.Name, Version: p.Version}
+}
+
+func (c *CommandGenerator) options() *analyzer.CommandOptions {
+	if c == nil {
+		return nil
+	}
+	return &analyzer.CommandOptions{Executable: c.Executable, Args: c.Args}
+}
+
+func (c *CdxgenConfig) options() *analyzer.CdxgenOptions {
+	if c == nil {
+		return nil
+	}
+	return &analyzer.CdxgenOptions{Args: c.Args, Env: c.Env, SpecVersion: c.SpecVersion, Timeout: minutes(c.Timeout)}
+}
+
+// minutes converts an optional number of minutes, zero if it is not set
+func minutes(m *int) time.Duration {
+	if m == nil {
+		return 0
+	}
+	return time.Duration(*m) * time.Minute
 }
 
 type ApplicationRepo struct {
@@ -1,7 +1,9 @@
 package config
 
 import (
+	"encoding/json"
 	"os"
+	"slices"
 	"testing"
 )
 
@@ -39,3 +41,36 @@ func TestLoadFromFile_ValidFile(t *testing.T) {
 		t.Errorf("unexpected DependencyTrack.Url: %s", settings.DependencyTrack.Url)
 	}
 }
+
+func TestProjectTypes_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name      string
+		json      string
+		want      ProjectTypes
+		wantError bool
+	}{
+		{name: "single type", json: `{"type": "java"}`, want: ProjectTypes{"java"}},
+		{name: "list of types", json: `{"type": ["java", "js"]}`, want: ProjectTypes{"java", "js"}},
+		{name: "empty type", json: `{"type": ""}`, want: nil},
+		{name: "invalid type", json: `{"type": 42}`, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var target RepoTarget
+			err := json.Unmarshal([]byte(tt.json), &target)
+			if tt.wantError {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(target.Type, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, target.Type)
+			}
+		})
+	}
+}
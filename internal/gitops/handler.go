@@ -2,22 +2,34 @@ package gitops
 
 import (
 	"central-cyclone/internal/analyzer"
+	"central-cyclone/internal/buildinfo"
 	"central-cyclone/internal/config"
 	"central-cyclone/internal/gittool"
+	"central-cyclone/internal/provenance"
 	"central-cyclone/internal/upload"
 	"context"
 	"fmt"
 	"log/slog"
+	"time"
 )
 
+// AppChange is a version of an application deployed to an environment, as read from a GitOps repo
+type AppChange struct {
+	AppName       string
+	Environment   string
+	Version       string
+	GitOpsRepoUrl string
+	GitOpsCommit  string // Commit of the GitOps repo the version was read from, empty if unknown
+}
+
 type AppChangedHandler interface {
-	HandleAppChange(ctx context.Context, applicationName, environment, version string) error
+	HandleAppChange(ctx context.Context, change AppChange) error
 }
 
 type NoOpsAppChangedHandler struct{}
 
-func (h NoOpsAppChangedHandler) HandleAppChange(ctx context.Context, applicationName, environment, version string) error {
-	slog.Debug("Handled App Change", "app", applicationName, "env", environment, "version", version)
+func (h NoOpsAppChangedHandler) HandleAppChange(ctx context.Context, change AppChange) error {
+	slog.Debug("Handled App Change", "app", change.AppName, "env", change.Environment, "version", change.Version)
 	return nil
 }
 
@@ -36,39 +48,73 @@ type CreateSbomChangeHandler struct {
 	gitTool                 gittool.Cloner
 	sbomAnalyzer            analyzer.Analyzer
 	dependencyTrackUploader upload.Uploader
+	repoLocks               keyedMutex
 }
 
-func (h CreateSbomChangeHandler) HandleAppChange(ctx context.Context, applicationName, environment, version string) error {
+func (h *CreateSbomChangeHandler) HandleAppChange(ctx context.Context, change AppChange) error {
+	applicationName, environment, version := change.AppName, change.Environment, change.Version
 
 	appRepoUrl, err := h.configProvider.GetApplicationRepo(applicationName)
 	if err != nil {
 		return fmt.Errorf("get application repo %q: %w", applicationName, err)
 	}
-	// Clone or update the repo and checkout the specific version
-	clonedRepo, err := h.gitTool.CloneOrUpdateRepo(appRepoUrl)
-	if err != nil {
-		return fmt.Errorf("clone repo %q: %w", appRepoUrl, err)
-	}
 
-	err = clonedRepo.CheckoutRevision(version)
+	worktree, err := h.checkoutRevision(appRepoUrl, version, h.configProvider.GetRevisionRules(applicationName))
 	if err != nil {
-		return fmt.Errorf("checkout %q: %w", version, err)
+		return err
 	}
+	defer func() {
+		if err := worktree.Remove(); err != nil {
+			slog.Warn("Could not remove worktree", "app", applicationName, "env", environment, "path", worktree.Path, "error", err)
+		}
+	}()
 
 	scanTarget, err := h.configProvider.GetScanTargetForApplication(applicationName, environment)
 	if err != nil {
 		return fmt.Errorf("get scan target %q/%q: %w", applicationName, environment, err)
 	}
 
-	sbom, err := h.sbomAnalyzer.AnalyzeProject(clonedRepo, scanTarget)
+	sbom, err := h.sbomAnalyzer.AnalyzeProject(ctx, worktree.ClonedRepo, scanTarget)
 	if err != nil {
 		return fmt.Errorf("analyze %q/%q: %w", applicationName, environment, err)
 	}
 
-	err = h.dependencyTrackUploader.UploadSBOM(ctx, sbom)
+	sbom.Data, err = provenance.Apply(sbom.Data, provenance.Provenance{
+		RepoUrl:       appRepoUrl,
+		Commit:        worktree.Hash,
+		Version:       version,
+		Environment:   environment,
+		GitOpsRepoUrl: change.GitOpsRepoUrl,
+		GitOpsCommit:  change.GitOpsCommit,
+		ToolVersion:   buildinfo.Version,
+		Timestamp:     time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("add provenance %q/%q: %w", applicationName, environment, err)
+	}
+
+	_, err = h.dependencyTrackUploader.UploadSBOM(ctx, sbom)
 	if err != nil {
 		return fmt.Errorf("upload SBOM %q/%q: %w", applicationName, environment, err)
 	}
 
 	return nil
 }
+
+// checkoutRevision clones or updates the app repo and checks out the version into its own worktree.
+// Apps within the same repo share a single clone, thus its update is serialized per repo.
+func (h *CreateSbomChangeHandler) checkoutRevision(appRepoUrl, version string, rules []gittool.RevisionRule) (gittool.RevisionWorktree, error) {
+	unlock := h.repoLocks.Lock(appRepoUrl)
+	defer unlock()
+
+	clonedRepo, err := h.gitTool.CloneOrUpdateRepo(appRepoUrl)
+	if err != nil {
+		return gittool.RevisionWorktree{}, fmt.Errorf("clone repo %q: %w", appRepoUrl, err)
+	}
+
+	worktree, err := h.gitTool.CreateRevisionWorktree(clonedRepo, version, rules)
+	if err != nil {
+		return gittool.RevisionWorktree{}, fmt.Errorf("checkout %q: %w", version, err)
+	}
+	return worktree, nil
+}
@@ -5,10 +5,12 @@ import (
 	"central-cyclone/internal/config"
 	"central-cyclone/internal/gittool"
 	"central-cyclone/internal/models"
+	"central-cyclone/internal/upload"
 	"context"
 	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -17,10 +19,12 @@ import (
 )
 
 type MockRepoCloner struct {
-	repo        gittool.ClonedRepo
-	err         error
-	receivedURL string
-	called      bool
+	repo         gittool.ClonedRepo
+	err          error
+	receivedURL  string
+	called       bool
+	worktreePath string
+	worktreeHash string
 }
 
 func (m *MockRepoCloner) CloneRepo(repoURL string) (gittool.ClonedRepo, error) {
@@ -33,6 +37,20 @@ func (m *MockRepoCloner) CloneOrUpdateRepo(repoURL string) (gittool.ClonedRepo,
 	return m.repo, m.err
 }
 
+func (m *MockRepoCloner) CreateRevisionWorktree(repo gittool.ClonedRepo, revision string, rules []gittool.RevisionRule) (gittool.RevisionWorktree, error) {
+	path, err := os.MkdirTemp("", "worktree-")
+	if err != nil {
+		return gittool.RevisionWorktree{}, err
+	}
+	m.worktreePath = path
+	worktree, err := repo.CreateRevisionWorktree(revision, path, rules)
+	if err != nil {
+		os.RemoveAll(path)
+	}
+	m.worktreeHash = worktree.Hash
+	return worktree, err
+}
+
 type MockAnalyzer struct {
 	receivedRepo   gittool.ClonedRepo
 	receivedTarget *analyzer.ScanTarget
@@ -41,7 +59,7 @@ type MockAnalyzer struct {
 	called         bool
 }
 
-func (m *MockAnalyzer) AnalyzeProject(repo gittool.ClonedRepo, target *analyzer.ScanTarget) (models.Sbom, error) {
+func (m *MockAnalyzer) AnalyzeProject(ctx context.Context, repo gittool.ClonedRepo, target *analyzer.ScanTarget) (models.Sbom, error) {
 	m.called = true
 	m.receivedRepo = repo
 	m.receivedTarget = target
@@ -54,10 +72,10 @@ type MockUploader struct {
 	called       bool
 }
 
-func (m *MockUploader) UploadSBOM(ctx context.Context, sbom models.Sbom) error {
+func (m *MockUploader) UploadSBOM(ctx context.Context, sbom models.Sbom) (*upload.UploadResult, error) {
 	m.called = true
 	m.receivedSbom = sbom
-	return m.err
+	return nil, m.err
 }
 
 func createTempGitRepoWithTag(t *testing.T, tag string) string {
@@ -113,7 +131,7 @@ func TestCreateSbomChangeHandler_HandleAppChange_Success(t *testing.T) {
 		Applications: []config.Application{
 			{
 				Name:     "my-app",
-				Type:     "go",
+				Type:     config.ProjectTypes{"go"},
 				RepoPath: stringPtr("src"),
 				Projects: []config.Project{
 					{
@@ -145,7 +163,7 @@ func TestCreateSbomChangeHandler_HandleAppChange_Success(t *testing.T) {
 		result: models.Sbom{
 			ProjectId:   "project-123",
 			ProjectType: "go",
-			Data:        "sbom-content",
+			Data:        `{"bomFormat":"CycloneDX","specVersion":"1.6","metadata":{"component":{"name":"my-app"}}}`,
 		},
 	}
 	mockUploader := &MockUploader{}
@@ -157,7 +175,8 @@ func TestCreateSbomChangeHandler_HandleAppChange_Success(t *testing.T) {
 		dependencyTrackUploader: mockUploader,
 	}
 
-	if err := handler.HandleAppChange(context.TODO(), "my-app", "prod", tag); err != nil {
+	change := AppChange{AppName: "my-app", Environment: "prod", Version: tag, GitOpsRepoUrl: "https://github.com/example/gitops.git", GitOpsCommit: "abc123"}
+	if err := handler.HandleAppChange(context.TODO(), change); err != nil {
 		t.Fatalf("expected success, got error: %v", err)
 	}
 
@@ -177,6 +196,14 @@ func TestCreateSbomChangeHandler_HandleAppChange_Success(t *testing.T) {
 		t.Fatal("expected scan target to be passed to analyzer")
 	}
 
+	if mockAnalyzer.receivedRepo.Path != mockCloner.worktreePath {
+		t.Fatalf("expected analyzer to receive the worktree %q, got %q", mockCloner.worktreePath, mockAnalyzer.receivedRepo.Path)
+	}
+
+	if _, err := os.Stat(mockCloner.worktreePath); !os.IsNotExist(err) {
+		t.Fatalf("expected worktree %q to be removed after analysis", mockCloner.worktreePath)
+	}
+
 	if mockAnalyzer.receivedTarget.ProjectId != "project-123" {
 		t.Fatalf("unexpected project id: %s", mockAnalyzer.receivedTarget.ProjectId)
 	}
@@ -184,6 +211,18 @@ func TestCreateSbomChangeHandler_HandleAppChange_Success(t *testing.T) {
 	if mockUploader.receivedSbom.ProjectId != "project-123" {
 		t.Fatalf("unexpected uploaded sbom project id: %s", mockUploader.receivedSbom.ProjectId)
 	}
+
+	for _, provenance := range []string{
+		`"name":"central-cyclone:repo.url","value":"` + tmpRepo + `"`,
+		`"name":"central-cyclone:repo.commit","value":"` + mockCloner.worktreeHash + `"`,
+		`"name":"central-cyclone:version","value":"v1.0.0"`,
+		`"name":"central-cyclone:gitops.environment","value":"prod"`,
+		`"name":"central-cyclone:gitops.repo.commit","value":"abc123"`,
+	} {
+		if !strings.Contains(mockUploader.receivedSbom.Data, provenance) {
+			t.Errorf("expected uploaded sbom to contain %s, got %s", provenance, mockUploader.receivedSbom.Data)
+		}
+	}
 }
 
 func TestCreateSbomChangeHandler_HandleAppChange_ConfigRepoError(t *testing.T) {
@@ -202,7 +241,7 @@ func TestCreateSbomChangeHandler_HandleAppChange_ConfigRepoError(t *testing.T) {
 		dependencyTrackUploader: &MockUploader{},
 	}
 
-	err = handler.HandleAppChange(context.TODO(), "missing-app", "prod", "v1.0.0")
+	err = handler.HandleAppChange(context.TODO(), AppChange{AppName: "missing-app", Environment: "prod", Version: "v1.0.0"})
 	if err == nil {
 		t.Fatal("expected an error when application repo is missing")
 	}
@@ -216,7 +255,7 @@ func TestCreateSbomChangeHandler_HandleAppChange_CloneError(t *testing.T) {
 		Applications: []config.Application{
 			{
 				Name:     "my-app",
-				Type:     "go",
+				Type:     config.ProjectTypes{"go"},
 				RepoPath: stringPtr("src"),
 				Projects: []config.Project{{Environment: "prod", ProjectId: stringPtr("project-123")}},
 			},
@@ -237,7 +276,7 @@ func TestCreateSbomChangeHandler_HandleAppChange_CloneError(t *testing.T) {
 		dependencyTrackUploader: &MockUploader{},
 	}
 
-	err = handler.HandleAppChange(context.TODO(), "my-app", "prod", tag)
+	err = handler.HandleAppChange(context.TODO(), AppChange{AppName: "my-app", Environment: "prod", Version: tag})
 	if !errors.Is(err, cloneErr) {
 		t.Fatalf("expected clone error, got: %v", err)
 	}
@@ -249,7 +288,7 @@ func TestCreateSbomChangeHandler_HandleAppChange_CheckoutTagError(t *testing.T)
 
 	settings := &config.Settings{
 		Applications: []config.Application{
-			{Name: "my-app", Type: "go", RepoPath: stringPtr("src"), Projects: []config.Project{{Environment: "prod", ProjectId: stringPtr("project-123")}}},
+			{Name: "my-app", Type: config.ProjectTypes{"go"}, RepoPath: stringPtr("src"), Projects: []config.Project{{Environment: "prod", ProjectId: stringPtr("project-123")}}},
 		},
 		ApplicationRepos: []config.ApplicationRepo{{Applications: []string{"my-app"}, RepoUrl: tmpRepo}},
 	}
@@ -268,7 +307,7 @@ func TestCreateSbomChangeHandler_HandleAppChange_CheckoutTagError(t *testing.T)
 		dependencyTrackUploader: &MockUploader{},
 	}
 
-	err = handler.HandleAppChange(context.TODO(), "my-app", "prod", "missing-tag")
+	err = handler.HandleAppChange(context.TODO(), AppChange{AppName: "my-app", Environment: "prod", Version: "missing-tag"})
 	if err == nil {
 		t.Fatal("expected error when checkout tag fails")
 	}
@@ -279,7 +318,7 @@ func TestCreateSbomChangeHandler_HandleAppChange_AnalysisError(t *testing.T) {
 	tmpRepo := createTempGitRepoWithTag(t, tag)
 
 	settings := &config.Settings{
-		Applications:     []config.Application{{Name: "my-app", Type: "go", RepoPath: stringPtr("src"), Projects: []config.Project{{Environment: "prod", ProjectId: stringPtr("project-123")}}}},
+		Applications:     []config.Application{{Name: "my-app", Type: config.ProjectTypes{"go"}, RepoPath: stringPtr("src"), Projects: []config.Project{{Environment: "prod", ProjectId: stringPtr("project-123")}}}},
 		ApplicationRepos: []config.ApplicationRepo{{Applications: []string{"my-app"}, RepoUrl: tmpRepo}},
 	}
 	configProvider, err := config.NewConfigProvider(settings)
@@ -297,7 +336,7 @@ func TestCreateSbomChangeHandler_HandleAppChange_AnalysisError(t *testing.T) {
 		dependencyTrackUploader: &MockUploader{},
 	}
 
-	err = handler.HandleAppChange(context.TODO(), "my-app", "prod", tag)
+	err = handler.HandleAppChange(context.TODO(), AppChange{AppName: "my-app", Environment: "prod", Version: tag})
 	if !errors.Is(err, analysisErr) {
 		t.Fatalf("expected analysis error, got: %v", err)
 	}
@@ -308,7 +347,7 @@ func TestCreateSbomChangeHandler_HandleAppChange_UploadError(t *testing.T) {
 	tmpRepo := createTempGitRepoWithTag(t, tag)
 
 	settings := &config.Settings{
-		Applications:     []config.Application{{Name: "my-app", Type: "go", RepoPath: stringPtr("src"), Projects: []config.Project{{Environment: "prod", ProjectId: stringPtr("project-123")}}}},
+		Applications:     []config.Application{{Name: "my-app", Type: config.ProjectTypes{"go"}, RepoPath: stringPtr("src"), Projects: []config.Project{{Environment: "prod", ProjectId: stringPtr("project-123")}}}},
 		ApplicationRepos: []config.ApplicationRepo{{Applications: []string{"my-app"}, RepoUrl: tmpRepo}},
 	}
 	configProvider, err := config.NewConfigProvider(settings)
@@ -316,7 +355,7 @@ func TestCreateSbomChangeHandler_HandleAppChange_UploadError(t *testing.T) {
 		t.Fatalf("failed to create config provider: %v", err)
 	}
 	mockCloner := &MockRepoCloner{repo: gittool.ClonedRepo{Path: tmpRepo, RepoUrl: tmpRepo}}
-	mockAnalyzer := &MockAnalyzer{result: models.Sbom{ProjectId: "project-123", ProjectType: "go", Data: "sbom-data"}}
+	mockAnalyzer := &MockAnalyzer{result: models.Sbom{ProjectId: "project-123", ProjectType: "go", Data: `{"bomFormat":"CycloneDX"}`}}
 	uploadErr := errors.New("upload failed")
 	mockUploader := &MockUploader{err: uploadErr}
 
@@ -327,7 +366,7 @@ func TestCreateSbomChangeHandler_HandleAppChange_UploadError(t *testing.T) {
 		dependencyTrackUploader: mockUploader,
 	}
 
-	err = handler.HandleAppChange(context.TODO(), "my-app", "prod", tag)
+	err = handler.HandleAppChange(context.TODO(), AppChange{AppName: "my-app", Environment: "prod", Version: tag})
 	if !errors.Is(err, uploadErr) {
 		t.Fatalf("expected upload error, got: %v", err)
 	}
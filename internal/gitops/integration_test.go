@@ -4,6 +4,7 @@ import (
 	"central-cyclone/internal/config"
 	"central-cyclone/internal/gittool"
 	"central-cyclone/internal/models"
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -38,6 +39,14 @@ func (w *tempWorkspace) CreateRepoFolder(repoURL string) (string, error) {
 	return target, nil
 }
 
+func (w *tempWorkspace) CreateWorktreeFolder(repoURL string) (string, error) {
+	return os.MkdirTemp(w.root, "worktree-")
+}
+
+func (w *tempWorkspace) GlobFilesInRepo(repoPath string, pattern string) ([]string, error) {
+	return nil, nil
+}
+
 func (w *tempWorkspace) ReadFileFromRepo(repoPath string, relativePath string) ([]byte, error) {
 	p := filepath.Join(repoPath, relativePath)
 	return os.ReadFile(p)
@@ -67,9 +76,10 @@ func TestSyncer_FullChain_LocalRepoClone(t *testing.T) {
 	if _, err := wt.Add("app/version.yaml"); err != nil {
 		t.Fatalf("add: %v", err)
 	}
-	if _, err := wt.Commit("add version", &git.CommitOptions{
+	commit, err := wt.Commit("add version", &git.CommitOptions{
 		Author: &object.Signature{Name: "Tester", Email: "t@example.com", When: time.Now()},
-	}); err != nil {
+	})
+	if err != nil {
 		t.Fatalf("commit: %v", err)
 	}
 
@@ -78,7 +88,7 @@ func TestSyncer_FullChain_LocalRepoClone(t *testing.T) {
 	cloner := gittool.CreateLocalGitCloner(tw)
 
 	// 3) create syncer using real cloner and temp workspace
-	s := NewSyncer(cloner, tw, NoOpsAppChangedHandler{})
+	s := NewSyncer(cloner, tw, NoOpsAppChangedHandler{}, SyncerOptions{})
 
 	// 4) prepare config to point at the local repo (use the source path as URL)
 	g := config.GitOpsRepo{
@@ -98,7 +108,7 @@ func TestSyncer_FullChain_LocalRepoClone(t *testing.T) {
 	}
 
 	// 5) run Init which will clone and extract
-	if err := s.Init([]config.GitOpsRepo{g}); err != nil {
+	if err := s.Init(context.Background(), []config.GitOpsRepo{g}); err != nil {
 		t.Fatalf("Init failed: %v", err)
 	}
 
@@ -118,4 +128,68 @@ func TestSyncer_FullChain_LocalRepoClone(t *testing.T) {
 	if as.CurrentVersion != "9.9.9" {
 		t.Fatalf("extracted version mismatch: %q", as.CurrentVersion)
 	}
+	if as.GitOpsCommit != commit.String() {
+		t.Fatalf("expected GitOps commit %q got %q", commit.String(), as.GitOpsCommit)
+	}
+}
+
+// newGitOpsSource creates a git repo with a committed app/version.yaml containing the version
+func newGitOpsSource(t *testing.T, version string) string {
+	t.Helper()
+	source := t.TempDir()
+	r, err := git.PlainInit(source, false)
+	if err != nil {
+		t.Fatalf("init repo: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(source, "app"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(source, "app", "version.yaml"), []byte("version: "+version+"\n"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	wt, err := r.Worktree()
+	if err != nil {
+		t.Fatalf("worktree: %v", err)
+	}
+	if _, err := wt.Add("app/version.yaml"); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if _, err := wt.Commit("add version", &git.CommitOptions{
+		Author: &object.Signature{Name: "Tester", Email: "t@example.com", When: time.Now()},
+	}); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	return source
+}
+
+func TestSyncer_ReconcileRepo_HandlesOnlyChangesOfTheRepo(t *testing.T) {
+	pushed := newGitOpsSource(t, "1.0.0")
+	other := newGitOpsSource(t, "2.0.0")
+	tw := newTempWorkspace(t)
+	handler := &recordingHandler{}
+	s := NewSyncer(gittool.CreateLocalGitCloner(tw), tw, handler, SyncerOptions{})
+
+	gitOpsRepo := func(url, appName string) config.GitOpsRepo {
+		return config.GitOpsRepo{
+			Url: url,
+			GitOpsApplications: []config.GitOpsApplication{{
+				ApplicationName:    appName,
+				VersionIdentifiers: []config.VersionIdentifier{{Environment: "prod", Filepath: "app/version.yaml", YamlPath: ".version"}},
+			}},
+		}
+	}
+	if err := s.Init(context.Background(), []config.GitOpsRepo{gitOpsRepo(pushed, "pushed-app"), gitOpsRepo(other, "other-app")}); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+
+	if err := s.ReconcileRepo(context.Background(), pushed); err != nil {
+		t.Fatalf("ReconcileRepo failed: %v", err)
+	}
+
+	if len(handler.changes) != 1 || handler.changes[0].AppName != "pushed-app" || handler.changes[0].GitOpsRepoUrl != pushed {
+		t.Errorf("expected only the change of the pushed repo to be handled, got %+v", handler.changes)
+	}
+	if s.state.GitOpsRepos[other].AppStates[AppStateKey{AppName: "other-app", Environment: "prod"}].Handled {
+		t.Error("expected the change of the other repo to be left to the next reconcile")
+	}
 }
@@ -0,0 +1,112 @@
+package gitops
+
+import (
+	"central-cyclone/internal/config"
+	"central-cyclone/internal/gittool"
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+)
+
+// PlanEntry describes an app change that would be analyzed and uploaded
+type PlanEntry struct {
+	AppName     string
+	Environment string
+	Version     string
+	Commit      string
+	Project     string // Project id or name@version
+	ProjectType string
+	Directory   string
+	Generator   string
+	Error       string // Set if the change could not be planned, e.g. as the version does not resolve
+}
+
+// PlanChangeHandler records the app changes instead of handling them. It clones the app repos and resolves
+// the versions to commits, but neither runs an analysis nor uploads anything.
+type PlanChangeHandler struct {
+	configProvider *config.ConfigProvider
+	gitTool        gittool.Cloner
+	repoLocks      keyedMutex
+	mu             sync.Mutex
+	entries        []PlanEntry
+}
+
+func NewPlanChangeHandler(configProvider *config.ConfigProvider, gitTool gittool.Cloner) *PlanChangeHandler {
+	return &PlanChangeHandler{configProvider: configProvider, gitTool: gitTool}
+}
+
+func (h *PlanChangeHandler) HandleAppChange(ctx context.Context, change AppChange) error {
+	applicationName, environment, version := change.AppName, change.Environment, change.Version
+	entry := PlanEntry{AppName: applicationName, Environment: environment, Version: version}
+
+	err := h.planAppChange(applicationName, environment, version, &entry)
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	h.mu.Lock()
+	h.entries = append(h.entries, entry)
+	h.mu.Unlock()
+
+	slog.Debug("Planned app change", "app", applicationName, "env", environment, "version", version, "commit", entry.Commit, "error", err)
+	return err
+}
+
+func (h *PlanChangeHandler) planAppChange(applicationName, environment, version string, entry *PlanEntry) error {
+	scanTarget, err := h.configProvider.GetScanTargetForApplication(applicationName, environment)
+	if err != nil {
+		return fmt.Errorf("get scan target %q/%q: %w", applicationName, environment, err)
+	}
+	entry.Project = scanTarget.Project()
+	entry.ProjectType = scanTarget.ProjectType()
+	entry.Generator = scanTarget.GetGenerator()
+	if scanTarget.Directory != nil {
+		entry.Directory = *scanTarget.Directory
+	}
+
+	appRepoUrl, err := h.configProvider.GetApplicationRepo(applicationName)
+	if err != nil {
+		return fmt.Errorf("get application repo %q: %w", applicationName, err)
+	}
+
+	unlock := h.repoLocks.Lock(appRepoUrl)
+	defer unlock()
+
+	clonedRepo, err := h.gitTool.CloneOrUpdateRepo(appRepoUrl)
+	if err != nil {
+		return fmt.Errorf("clone repo %q: %w", appRepoUrl, err)
+	}
+
+	commit, err := clonedRepo.ResolveRevision(version, h.configProvider.GetRevisionRules(applicationName))
+	if err != nil {
+		return fmt.Errorf("resolve %q: %w", version, err)
+	}
+	entry.Commit = commit
+
+	return nil
+}
+
+// Entries returns the planned app changes, sorted by app and environment
+func (h *PlanChangeHandler) Entries() []PlanEntry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	entries := slices.Clone(h.entries)
+	slices.SortFunc(entries, func(a, b PlanEntry) int {
+		return cmp.Or(cmp.Compare(a.AppName, b.AppName), cmp.Compare(a.Environment, b.Environment))
+	})
+	return entries
+}
+
+// ReadOnlyStateStore loads the state of the wrapped store, but never saves it.
+// Used by dry runs, such that planned versions are not marked as handled.
+type ReadOnlyStateStore struct {
+	StateStore
+}
+
+func (s ReadOnlyStateStore) Save(state PersistedState) error {
+	return nil
+}
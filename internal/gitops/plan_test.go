@@ -0,0 +1,71 @@
+package gitops
+
+import (
+	"central-cyclone/internal/config"
+	"central-cyclone/internal/gittool"
+	"context"
+	"testing"
+)
+
+func TestPlanChangeHandler_RecordsResolvedChanges(t *testing.T) {
+	tag := "v1.0.0"
+	tmpRepo := createTempGitRepoWithTag(t, tag)
+
+	configProvider, err := config.NewConfigProvider(&config.Settings{
+		Applications: []config.Application{
+			{
+				Name:     "my-app",
+				Type:     config.ProjectTypes{"go"},
+				RepoPath: stringPtr("src"),
+				Projects: []config.Project{
+					{Environment: "prod", ProjectId: stringPtr("project-prod")},
+					{Environment: "dev", ProjectId: stringPtr("project-dev")},
+				},
+				RevisionRules: []config.RevisionRule{{Match: `^\d+\.\d+\.\d+$`, Revision: "v$0"}},
+			},
+		},
+		ApplicationRepos: []config.ApplicationRepo{
+			{Applications: []string{"my-app"}, RepoUrl: tmpRepo},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create config provider: %v", err)
+	}
+	mockCloner := &MockRepoCloner{repo: gittool.ClonedRepo{Path: tmpRepo, RepoUrl: tmpRepo}}
+	handler := NewPlanChangeHandler(configProvider, mockCloner)
+
+	if err := handler.HandleAppChange(context.TODO(), AppChange{AppName: "my-app", Environment: "prod", Version: "1.0.0"}); err != nil {
+		t.Fatalf("expected success, got error: %v", err)
+	}
+	if err := handler.HandleAppChange(context.TODO(), AppChange{AppName: "my-app", Environment: "dev", Version: "does-not-exist"}); err == nil {
+		t.Fatal("expected error for unresolvable version")
+	}
+
+	entries := handler.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+
+	dev, prod := entries[0], entries[1]
+	if dev.Environment != "dev" || dev.Error == "" || dev.Commit != "" {
+		t.Errorf("expected failed dev entry, got %+v", dev)
+	}
+	if prod.Environment != "prod" || prod.Error != "" || len(prod.Commit) != 40 {
+		t.Errorf("expected resolved prod entry, got %+v", prod)
+	}
+	if prod.Project != "project-prod" || prod.ProjectType != "go" || prod.Directory != "src" {
+		t.Errorf("expected scan target of prod in entry, got %+v", prod)
+	}
+}
+
+func TestReadOnlyStateStore_DoesNotSave(t *testing.T) {
+	stateStore := &MockStateStore{}
+	readOnly := ReadOnlyStateStore{StateStore: stateStore}
+
+	if err := readOnly.Save(PersistedState{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(stateStore.saved) != 0 {
+		t.Errorf("expected no save on the wrapped store, got %d", len(stateStore.saved))
+	}
+}
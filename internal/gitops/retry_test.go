@@ -0,0 +1,28 @@
+package gitops
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, InitialBackoff: 10 * time.Minute, MaxBackoff: time.Hour}
+
+	expected := []time.Duration{10 * time.Minute, 20 * time.Minute, 40 * time.Minute, time.Hour, time.Hour}
+	for i, want := range expected {
+		if got := policy.backoff(i + 1); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", i+1, got, want)
+		}
+	}
+}
+
+func TestRetryPolicy_WithDefaults(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3}.withDefaults()
+
+	if policy.MaxAttempts != 3 {
+		t.Errorf("Expected configured max attempts to be kept, got %d", policy.MaxAttempts)
+	}
+	if policy.InitialBackoff != DefaultRetryPolicy().InitialBackoff || policy.MaxBackoff != DefaultRetryPolicy().MaxBackoff {
+		t.Errorf("Expected default backoff, got %+v", policy)
+	}
+}
@@ -0,0 +1,114 @@
+package gitops
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const stateFileVersion = 1
+
+// StateStore persists the SyncState across restarts, such that already handled versions
+// are not analyzed again.
+type StateStore interface {
+	Load() (PersistedState, error)
+	Save(state PersistedState) error
+}
+
+type PersistedState struct {
+	Version     int                            `json:"version"`
+	GitOpsRepos map[string][]PersistedAppState `json:"gitOpsRepos"` // Key: Repo URL
+}
+
+type PersistedAppState struct {
+	AppName        string    `json:"appName"`
+	Environment    string    `json:"environment"`
+	CurrentVersion string    `json:"currentVersion"`
+	Handled        bool      `json:"handled"`
+	LastHandledAt  time.Time `json:"lastHandledAt"`
+	Attempts       int       `json:"attempts,omitempty"`
+	LastError      string    `json:"lastError,omitempty"`
+	NextAttemptAt  time.Time `json:"nextAttemptAt,omitzero"`
+	Failed         bool      `json:"failed,omitempty"`
+}
+
+// findAppState returns the persisted state for the given repo and app key if present
+func (p PersistedState) findAppState(repoUrl string, key AppStateKey) (PersistedAppState, bool) {
+	for _, appState := range p.GitOpsRepos[repoUrl] {
+		if appState.AppName == key.AppName && appState.Environment == key.Environment {
+			return appState, true
+		}
+	}
+	return PersistedAppState{}, false
+}
+
+// NoOpStateStore keeps the state in memory only. Every restart starts with an empty state.
+type NoOpStateStore struct{}
+
+func (s NoOpStateStore) Load() (PersistedState, error) {
+	return PersistedState{GitOpsRepos: make(map[string][]PersistedAppState)}, nil
+}
+
+func (s NoOpStateStore) Save(state PersistedState) error {
+	return nil
+}
+
+// FileStateStore persists the state as a JSON file
+type FileStateStore struct {
+	path string
+}
+
+func NewFileStateStore(path string) *FileStateStore {
+	return &FileStateStore{path: path}
+}
+
+// Load reads the state file. A missing file results in an empty state.
+func (s *FileStateStore) Load() (PersistedState, error) {
+	state := PersistedState{GitOpsRepos: make(map[string][]PersistedAppState)}
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return state, fmt.Errorf("failed to read state file %s: %w", s.path, err)
+	}
+
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("failed to parse state file %s: %w", s.path, err)
+	}
+	if state.Version != stateFileVersion {
+		return state, fmt.Errorf("unsupported state file version %d in %s", state.Version, s.path)
+	}
+	if state.GitOpsRepos == nil {
+		state.GitOpsRepos = make(map[string][]PersistedAppState)
+	}
+
+	return state, nil
+}
+
+// Save writes the state to a temporary file first and renames it afterwards,
+// such that a crash never leaves a partially written state file behind.
+func (s *FileStateStore) Save(state PersistedState) error {
+	state.Version = stateFileVersion
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create state folder: %w", err)
+	}
+
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write state file %s: %w", tmpPath, err)
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		return fmt.Errorf("failed to replace state file %s: %w", s.path, err)
+	}
+	return nil
+}
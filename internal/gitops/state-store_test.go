@@ -0,0 +1,69 @@
+package gitops
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileStateStore_Load_MissingFile(t *testing.T) {
+	store := NewFileStateStore(filepath.Join(t.TempDir(), "state.json"))
+
+	state, err := store.Load()
+	if err != nil {
+		t.Fatalf("expected no error for missing file, got %v", err)
+	}
+	if state.GitOpsRepos == nil || len(state.GitOpsRepos) != 0 {
+		t.Errorf("expected empty state, got %+v", state)
+	}
+}
+
+func TestFileStateStore_SaveAndLoad(t *testing.T) {
+	store := NewFileStateStore(filepath.Join(t.TempDir(), "nested", "state.json"))
+
+	saved := PersistedState{
+		GitOpsRepos: map[string][]PersistedAppState{
+			"https://github.com/example/gitops.git": {
+				{AppName: "app1", Environment: "prod", CurrentVersion: "1.0.0", Handled: true},
+			},
+		},
+	}
+	if err := store.Save(saved); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	appState, ok := loaded.findAppState("https://github.com/example/gitops.git", AppStateKey{AppName: "app1", Environment: "prod"})
+	if !ok {
+		t.Fatal("expected persisted app state to be found")
+	}
+	if appState.CurrentVersion != "1.0.0" || !appState.Handled {
+		t.Errorf("unexpected app state: %+v", appState)
+	}
+}
+
+func TestFileStateStore_Load_UnsupportedVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(path, []byte(`{"version": 99, "gitOpsRepos": {}}`), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	if _, err := NewFileStateStore(path).Load(); err == nil {
+		t.Fatal("expected error for unsupported state file version")
+	}
+}
+
+func TestFileStateStore_Load_InvalidJson(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(path, []byte(`not json`), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	if _, err := NewFileStateStore(path).Load(); err == nil {
+		t.Fatal("expected error for invalid state file")
+	}
+}
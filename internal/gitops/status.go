@@ -0,0 +1,88 @@
+package gitops
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// StatusReport is a snapshot of the SyncState, e.g. to be served by the status API
+type StatusReport struct {
+	Ready       bool               `json:"ready"`
+	GitOpsRepos []GitOpsRepoStatus `json:"gitOpsRepos"`
+}
+
+type GitOpsRepoStatus struct {
+	Url  string      `json:"url"`
+	Apps []AppStatus `json:"apps"`
+}
+
+type AppStatus struct {
+	AppName        string     `json:"appName"`
+	Environment    string     `json:"environment"`
+	CurrentVersion string     `json:"currentVersion"`
+	Handled        bool       `json:"handled"`
+	LastError      string     `json:"lastError,omitempty"`
+	LastHandledAt  *time.Time `json:"lastHandledAt,omitempty"`
+	Attempts       int        `json:"attempts,omitempty"`
+	NextAttemptAt  *time.Time `json:"nextAttemptAt,omitempty"`
+	Failed         bool       `json:"failed"`
+}
+
+// RetryState describes why an unhandled app change is skipped by Reconcile, empty if it is not skipped
+func (a AppStatus) RetryState() string {
+	switch {
+	case a.Handled:
+		return ""
+	case a.Failed:
+		return fmt.Sprintf("parked after %d attempts", a.Attempts)
+	case a.NextAttemptAt != nil:
+		return fmt.Sprintf("backoff until %s", a.NextAttemptAt.Format(time.RFC3339))
+	}
+	return ""
+}
+
+// Status returns a snapshot of the current state, sorted by repo, app and environment
+func (s *Syncer) Status() StatusReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	report := StatusReport{
+		Ready:       s.Ready(),
+		GitOpsRepos: []GitOpsRepoStatus{},
+	}
+
+	for repoUrl, repoState := range s.state.GitOpsRepos {
+		repoStatus := GitOpsRepoStatus{Url: repoUrl, Apps: []AppStatus{}}
+		for key, appState := range repoState.AppStates {
+			appStatus := AppStatus{
+				AppName:        key.AppName,
+				Environment:    key.Environment,
+				CurrentVersion: appState.CurrentVersion,
+				Handled:        appState.Handled,
+				LastError:      appState.LastError,
+				Attempts:       appState.Attempts,
+				Failed:         appState.Failed,
+			}
+			if !appState.LastHandledAt.IsZero() {
+				lastHandledAt := appState.LastHandledAt
+				appStatus.LastHandledAt = &lastHandledAt
+			}
+			if !appState.NextAttemptAt.IsZero() {
+				nextAttemptAt := appState.NextAttemptAt
+				appStatus.NextAttemptAt = &nextAttemptAt
+			}
+			repoStatus.Apps = append(repoStatus.Apps, appStatus)
+		}
+		slices.SortFunc(repoStatus.Apps, func(a, b AppStatus) int {
+			return cmp.Or(cmp.Compare(a.AppName, b.AppName), cmp.Compare(a.Environment, b.Environment))
+		})
+		report.GitOpsRepos = append(report.GitOpsRepos, repoStatus)
+	}
+	slices.SortFunc(report.GitOpsRepos, func(a, b GitOpsRepoStatus) int {
+		return cmp.Compare(a.Url, b.Url)
+	})
+
+	return report
+}
@@ -2,6 +2,8 @@ package gitops
 
 import (
 	"central-cyclone/internal/gittool"
+	"central-cyclone/internal/query"
+	"time"
 )
 
 type SyncState struct {
@@ -17,16 +19,36 @@ type GitOpsAppState struct {
 	AppName           string
 	VersionIdentifier VersionIdentifier
 	CurrentVersion    string
+	GitOpsCommit      string // Commit of the GitOps repo the current version was read from, empty if unknown
 	Handled           bool
+	LastError         string    // Error of the last failed attempt to handle the current version
+	LastHandledAt     time.Time // Zero if no version was handled yet
+	Attempts          int       // Failed attempts to handle the current version
+	NextAttemptAt     time.Time // Zero if the current version can be handled right away
+	Failed            bool      // Parked after the maximum attempts, until the version changes
 }
 
 type VersionIdentifier struct {
-	env      string
-	filePath string
-	yamlPath string
+	env       string
+	filePath  string
+	yamlPath  string
+	extractor query.ValueExtractor // Extractor of the configured kind, evaluating the yamlPath if required
 }
 
 type AppStateKey struct {
 	AppName     string
 	Environment string
 }
+
+// resetRetries clears the retry bookkeeping, e.g. once the version changes
+func (a *GitOpsAppState) resetRetries() {
+	a.LastError = ""
+	a.Attempts = 0
+	a.NextAttemptAt = time.Time{}
+	a.Failed = false
+}
+
+// dueAt reports whether the app state has to be handled at the given time
+func (a *GitOpsAppState) dueAt(now time.Time) bool {
+	return !a.Handled && !a.Failed && !now.Before(a.NextAttemptAt)
+}
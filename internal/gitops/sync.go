@@ -3,49 +3,113 @@ package gitops
 import (
 	"central-cyclone/internal/config"
 	"central-cyclone/internal/gittool"
+	"central-cyclone/internal/metrics"
 	"central-cyclone/internal/query"
 	"central-cyclone/internal/workspace"
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type Syncer struct {
-	state            SyncState
-	gitTool          gittool.Cloner
-	workspace        workspace.Workspace
-	valueExtractor   query.ValueExtractor
-	appChangeHandler AppChangedHandler
+	state               SyncState
+	persistedState      PersistedState
+	gitTool             gittool.Cloner
+	workspace           workspace.Workspace
+	appChangeHandler    AppChangedHandler
+	stateStore          StateStore
+	maxConcurrency      int
+	retryPolicy         RetryPolicy
+	environmentResolver EnvironmentResolver
+	now                 func() time.Time
+	mu                  sync.Mutex // Guards the state, which is updated by concurrent handlers and read by status requests
+	snapshotSeq         uint64     // Number of the last state snapshot, guarded by mu
+	saveMu              sync.Mutex // Serializes writes to the state store, such that they do not block state updates
+	savedSeq            uint64     // Number of the last saved state snapshot, guarded by saveMu
+	reconcileMu         sync.Mutex // Serializes reconciles triggered by the ticker and by webhooks
+	ready               atomic.Bool
 }
 
-func NewSyncer(gitTool gittool.Cloner, workspace workspace.Workspace, changedHandler AppChangedHandler) *Syncer {
+type SyncerOptions struct {
+	StateStore     StateStore  // Optional, defaults to NoOpStateStore
+	MaxConcurrency int         // Optional, maximum number of app changes handled at the same time, defaults to 1
+	RetryPolicy    RetryPolicy // Optional, unset values default to the DefaultRetryPolicy
+	// Optional, pairs environments discovered by templated version identifiers with project environments.
+	// Defaults to using the discovered environments as they are.
+	EnvironmentResolver EnvironmentResolver
+}
+
+func NewSyncer(gitTool gittool.Cloner, workspace workspace.Workspace, changedHandler AppChangedHandler, options SyncerOptions) *Syncer {
+	stateStore := options.StateStore
+	if stateStore == nil {
+		stateStore = NoOpStateStore{}
+	}
+	environmentResolver := options.EnvironmentResolver
+	if environmentResolver == nil {
+		environmentResolver = identityEnvironmentResolver{}
+	}
+	maxConcurrency := options.MaxConcurrency
+	if maxConcurrency < 1 {
+		maxConcurrency = 1
+	}
+
 	return &Syncer{
 		state: SyncState{
 			GitOpsRepos: make(map[string]*GitOpsRepoState),
 		},
-		gitTool:          gitTool,
-		workspace:        workspace,
-		valueExtractor:   query.NewYqValueExtractor(),
-		appChangeHandler: changedHandler,
+		gitTool:             gitTool,
+		workspace:           workspace,
+		appChangeHandler:    changedHandler,
+		stateStore:          stateStore,
+		maxConcurrency:      maxConcurrency,
+		retryPolicy:         options.RetryPolicy.withDefaults(),
+		environmentResolver: environmentResolver,
+		now:                 time.Now,
 	}
 }
 
-func (s *Syncer) Init(gitOpsRepos []config.GitOpsRepo) error {
-	s.state.GitOpsRepos = make(map[string]*GitOpsRepoState)
+// Init clones the GitOps repos and reads the deployed versions. It stops before the next repo if the context is cancelled.
+func (s *Syncer) Init(ctx context.Context, gitOpsRepos []config.GitOpsRepo) error {
+	repoStates := make(map[string]*GitOpsRepoState)
+
+	persistedState, err := s.stateStore.Load()
+	if err != nil {
+		slog.Warn("Could not load persisted state, all versions will be handled again", "error", err)
+		persistedState = PersistedState{GitOpsRepos: make(map[string][]PersistedAppState)}
+	}
+	s.persistedState = persistedState
 
 	for _, repo := range gitOpsRepos {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("initialization cancelled: %w", err)
+		}
 		repoState, err := s.initGitOpsRepo(repo)
 		if err != nil {
 			slog.Error("Failed to initialize GitOps repo", "repoUrl", repo.Url, "error", err)
 			return fmt.Errorf("failed to initialize GitOps repo %s: %w", repo.Url, err)
 		}
-		s.state.GitOpsRepos[repo.Url] = &repoState
+		repoStates[repo.Url] = &repoState
 		slog.Info("Successfully initialized GitOps repo", "repoUrl", repo.Url, "apps", len(repoState.AppStates))
 	}
 
+	s.mu.Lock()
+	s.state.GitOpsRepos = repoStates
+	s.mu.Unlock()
+	s.ready.Store(true)
+	s.updateUnhandledAppStatesMetric()
+
 	return nil
 }
 
+// Ready reports whether Init has succeeded
+func (s *Syncer) Ready() bool {
+	return s.ready.Load()
+}
+
 func (s *Syncer) initGitOpsRepo(gitOpsRepo config.GitOpsRepo) (GitOpsRepoState, error) {
 	clonedRepo, err := s.gitTool.CloneRepo(gitOpsRepo.Url)
 	if err != nil {
@@ -54,12 +118,21 @@ func (s *Syncer) initGitOpsRepo(gitOpsRepo config.GitOpsRepo) (GitOpsRepoState,
 	}
 
 	appStates := make(map[AppStateKey]*GitOpsAppState)
-	extractor := query.NewYqValueExtractor()
+	commit := currentCommit(clonedRepo)
 
 	for _, app := range gitOpsRepo.GitOpsApplications {
-		for _, versionIdentifier := range app.VersionIdentifiers {
+		versionIdentifiers, err := s.expandVersionIdentifiers(clonedRepo.Path, app)
+		if err != nil {
+			slog.Error("Failed to expand templated version identifiers", "app", app.ApplicationName, "error", err)
+			return GitOpsRepoState{}, err
+		}
 
-			internalVersionIdentfier := mapToInternalVersionIdentifer(versionIdentifier)
+		for _, versionIdentifier := range versionIdentifiers {
+
+			internalVersionIdentfier, err := mapToInternalVersionIdentifer(versionIdentifier)
+			if err != nil {
+				return GitOpsRepoState{}, fmt.Errorf("invalid version identifier of app %s: %w", app.ApplicationName, err)
+			}
 
 			versionIdentifierFile, err := s.workspace.ReadFileFromRepo(clonedRepo.Path, internalVersionIdentfier.filePath)
 			if err != nil {
@@ -71,27 +144,29 @@ func (s *Syncer) initGitOpsRepo(gitOpsRepo config.GitOpsRepo) (GitOpsRepoState,
 				return GitOpsRepoState{}, fmt.Errorf("failed to read file %s: %w", versionIdentifier.Filepath, err)
 			}
 
-			version, err := extractor.ExtractValue(versionIdentifierFile, versionIdentifier.YamlPath)
+			version, err := internalVersionIdentfier.extractor.ExtractValue(versionIdentifierFile, internalVersionIdentfier.yamlPath)
 			if err != nil {
 				slog.Error("Failed to extract version from file",
 					"app", app.ApplicationName,
 					"environment", versionIdentifier.Environment,
 					"filepath", versionIdentifier.Filepath,
+					"kind", versionIdentifier.GetKind(),
 					"yamlPath", versionIdentifier.YamlPath,
 					"error", err)
 				return GitOpsRepoState{}, fmt.Errorf("failed to extract version: %w", err)
 			}
 
-			appState := GitOpsAppState{
-				AppName:           app.ApplicationName,
-				VersionIdentifier: mapToInternalVersionIdentifer(versionIdentifier),
-				CurrentVersion:    version,
-				Handled:           false,
-			}
 			appStateKey := AppStateKey{
 				AppName:     app.ApplicationName,
 				Environment: versionIdentifier.Environment,
 			}
+			appState := GitOpsAppState{
+				AppName:           app.ApplicationName,
+				VersionIdentifier: internalVersionIdentfier,
+				CurrentVersion:    version,
+				GitOpsCommit:      commit,
+			}
+			s.restorePersistedAppState(gitOpsRepo.Url, appStateKey, &appState)
 			appStates[appStateKey] = &appState
 
 			slog.Info("Extracted app version",
@@ -104,22 +179,147 @@ func (s *Syncer) initGitOpsRepo(gitOpsRepo config.GitOpsRepo) (GitOpsRepoState,
 	return GitOpsRepoState{Repo: clonedRepo, AppStates: appStates}, nil
 }
 
-func mapToInternalVersionIdentifer(configIdentifier config.VersionIdentifier) VersionIdentifier {
+// currentCommit returns the checked out commit of the GitOps repo, which is passed on as provenance of the read versions
+func currentCommit(repo gittool.ClonedRepo) string {
+	commit, err := repo.GetCurrentRevision()
+	if err != nil {
+		slog.Warn("Could not get commit of GitOps repo", "repoUrl", repo.RepoUrl, "error", err)
+		return ""
+	}
+	return commit
+}
+
+func mapToInternalVersionIdentifer(configIdentifier config.VersionIdentifier) (VersionIdentifier, error) {
+	extractor, err := newValueExtractor(configIdentifier)
+	if err != nil {
+		return VersionIdentifier{}, err
+	}
 	return VersionIdentifier{
-		env:      configIdentifier.Environment,
-		filePath: configIdentifier.Filepath,
-		yamlPath: configIdentifier.YamlPath,
+		env:       configIdentifier.Environment,
+		filePath:  configIdentifier.Filepath,
+		yamlPath:  configIdentifier.YamlPath,
+		extractor: extractor,
+	}, nil
+}
+
+// newValueExtractor creates the extractor for the kind of the version identifier.
+// If configured, it is decorated to map an image reference to the git revision.
+func newValueExtractor(configIdentifier config.VersionIdentifier) (query.ValueExtractor, error) {
+	var extractor query.ValueExtractor
+	switch configIdentifier.GetKind() {
+	case config.VersionIdentifierKindYq:
+		extractor = query.NewYqValueExtractor()
+	case config.VersionIdentifierKindArgoApplication:
+		extractor = query.NewArgoApplicationExtractor(configIdentifier.ResourceName)
+	case config.VersionIdentifierKindKustomizeImage:
+		extractor = query.NewKustomizeImageExtractor(configIdentifier.ImageName)
+	case config.VersionIdentifierKindHelmRelease:
+		extractor = query.NewHelmReleaseExtractor(configIdentifier.ResourceName)
+	default:
+		return nil, fmt.Errorf("unknown version identifier kind '%s'", configIdentifier.Kind)
+	}
+
+	if image := configIdentifier.Image; image != nil {
+		return query.NewImageRevisionExtractor(extractor, image.Use, image.StripPrefix, image.RevisionPattern)
 	}
+	return extractor, nil
 }
 
-func (s *Syncer) Reconcile() {
+// Reconcile updates all GitOps repos and handles the changed app versions.
+// It returns once all started handlers have finished. The returned error joins the errors
+// of all repos that could not be updated and all app changes that failed in this run.
+func (s *Syncer) Reconcile(ctx context.Context) error {
+	s.reconcileMu.Lock()
+	defer s.reconcileMu.Unlock()
+
+	metrics.ReconcileIterations.Inc()
+	defer s.updateUnhandledAppStatesMetric()
+
+	var errs []error
 	for _, repoState := range s.state.GitOpsRepos {
 		err := s.reconcileGitOpsRepo(repoState)
 		if err != nil {
 			slog.Warn("Error reconciling GitOps repo, skipping to next", "repo", repoState.Repo.RepoUrl, "error", err)
+			errs = append(errs, fmt.Errorf("reconcile GitOps repo %s: %w", repoState.Repo.RepoUrl, err))
+		}
+	}
+
+	errs = append(errs, s.checkUnhandledChanges(ctx, ""))
+	return errors.Join(errs...)
+}
+
+// ReconcileRepo updates a single GitOps repo, e.g. after a push webhook, and handles the unhandled changes of
+// this repo. Changes of other repos are left to the next Reconcile, which also updates their repos.
+// It returns once all started handlers have finished, with the joined errors of the failed app changes.
+func (s *Syncer) ReconcileRepo(ctx context.Context, repoUrl string) error {
+	s.reconcileMu.Lock()
+	defer s.reconcileMu.Unlock()
+
+	metrics.ReconcileIterations.Inc()
+	defer s.updateUnhandledAppStatesMetric()
+
+	s.mu.Lock()
+	repoState, ok := s.state.GitOpsRepos[repoUrl]
+	s.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("unknown GitOps repo %s", repoUrl)
+	}
+
+	if err := s.reconcileGitOpsRepo(repoState); err != nil {
+		return err
+	}
+
+	return s.checkUnhandledChanges(ctx, repoUrl)
+}
+
+// ReconcileOnce reconciles all GitOps repos for a single run, e.g. as CronJob. As there is no later run,
+// changes waiting for their backoff are handled right away, and versions parked in a previous run are
+// returned as errors together with the errors of Reconcile.
+func (s *Syncer) ReconcileOnce(ctx context.Context) error {
+	parked := make(map[*GitOpsAppState]string)
+	s.mu.Lock()
+	for _, repoState := range s.state.GitOpsRepos {
+		for _, appState := range repoState.AppStates {
+			if appState.Failed {
+				parked[appState] = appState.CurrentVersion
+			}
 		}
 	}
+	s.mu.Unlock()
+	s.clearBackoffs(false)
 
+	errs := []error{s.Reconcile(ctx)}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for appState, version := range parked {
+		// A changed version was handled in this run, its failure is already part of the errors of Reconcile
+		if appState.Failed && appState.CurrentVersion == version {
+			errs = append(errs, fmt.Errorf("handle %s/%s version %s: parked after %d failed attempts: %s", appState.AppName, appState.VersionIdentifier.env, appState.CurrentVersion, appState.Attempts, appState.LastError))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ReconcileAll reconciles all GitOps repos like Reconcile, but also handles the changes waiting for their backoff
+// and the parked versions, e.g. to plan every unhandled change in a dry run
+func (s *Syncer) ReconcileAll(ctx context.Context) error {
+	s.clearBackoffs(true)
+	return s.Reconcile(ctx)
+}
+
+// clearBackoffs makes the app states waiting for their backoff due, including the parked ones if includeParked is set
+func (s *Syncer) clearBackoffs(includeParked bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, repoState := range s.state.GitOpsRepos {
+		for _, appState := range repoState.AppStates {
+			appState.NextAttemptAt = time.Time{}
+			if includeParked {
+				appState.Failed = false
+			}
+		}
+	}
 }
 
 func (s *Syncer) reconcileGitOpsRepo(repoState *GitOpsRepoState) error {
@@ -131,39 +331,213 @@ func (s *Syncer) reconcileGitOpsRepo(repoState *GitOpsRepoState) error {
 	}
 
 	if updated {
+		commit := currentCommit(repoState.Repo)
 		for _, appstate := range repoState.AppStates {
 			maybeNewVersion, err := s.getVersionForApp(repoState.Repo, appstate)
 			if err != nil {
 				return err
 			}
 
+			s.mu.Lock()
 			if appstate.CurrentVersion != maybeNewVersion {
 				appstate.CurrentVersion = maybeNewVersion
+				appstate.GitOpsCommit = commit
 				appstate.Handled = false
+				appstate.resetRetries()
 			}
+			s.mu.Unlock()
 		}
 	}
 
-	s.checkUnhandledChanges(repoState)
-
 	return nil
 }
 
-// Checks for unhandled changes and calls the registered handler
-func (s *Syncer) checkUnhandledChanges(repoState *GitOpsRepoState) {
-	for _, appState := range repoState.AppStates {
+// Checks for unhandled changes of the repo, or of all repos if repoUrl is empty, and calls the registered handler.
+// At most maxConcurrency handlers run at the same time. Returns once all handlers have finished,
+// with the joined errors of the failed app changes or the context error, if cancelled.
+func (s *Syncer) checkUnhandledChanges(ctx context.Context, repoUrl string) error {
+	semaphore := make(chan struct{}, s.maxConcurrency)
+	var wg sync.WaitGroup
+	var errsMu sync.Mutex
+	var errs []error
 
-		if appState.Handled {
+	now := s.now()
+
+	for gitOpsRepoUrl, repoState := range s.state.GitOpsRepos {
+		if repoUrl != "" && gitOpsRepoUrl != repoUrl {
 			continue
 		}
-		// ToDo, proper Go routine
-		if err := s.appChangeHandler.HandleAppChange(context.TODO(), appState.AppName, appState.VersionIdentifier.env, appState.CurrentVersion); err != nil {
-			slog.Error("Failed to handle app change, will be retired on the next run", "app", appState.AppName, "env", appState.VersionIdentifier.env, "version", appState.CurrentVersion, "error", err)
-			continue
+		for _, appState := range repoState.AppStates {
+			s.mu.Lock()
+			due := appState.dueAt(now)
+			s.mu.Unlock()
+			if !due {
+				continue
+			}
+
+			select {
+			case semaphore <- struct{}{}:
+			case <-ctx.Done():
+			}
+			if ctx.Err() != nil {
+				slog.Info("Reconcile cancelled, remaining app changes will be handled on the next run")
+				wg.Wait()
+				return errors.Join(append(errs, ctx.Err())...)
+			}
+
+			wg.Add(1)
+			go func(appState *GitOpsAppState) {
+				defer wg.Done()
+				defer func() { <-semaphore }()
+				if err := s.handleAppChange(ctx, gitOpsRepoUrl, appState); err != nil {
+					errsMu.Lock()
+					errs = append(errs, err)
+					errsMu.Unlock()
+				}
+			}(appState)
+		}
+	}
+
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// handleAppChange calls the handler and updates the app state with its result.
+// A failed change is returned with the app, environment and version.
+func (s *Syncer) handleAppChange(ctx context.Context, repoUrl string, appState *GitOpsAppState) error {
+	s.mu.Lock()
+	change := AppChange{
+		AppName:       appState.AppName,
+		Environment:   appState.VersionIdentifier.env,
+		Version:       appState.CurrentVersion,
+		GitOpsRepoUrl: repoUrl,
+		GitOpsCommit:  appState.GitOpsCommit,
+	}
+	s.mu.Unlock()
+
+	err := s.appChangeHandler.HandleAppChange(ctx, change)
+
+	s.mu.Lock()
+	if err != nil && ctx.Err() != nil {
+		s.mu.Unlock()
+		// Cancelled on shutdown, which neither counts as an attempt nor delays the next run
+		slog.Warn("App change cancelled, will be handled on the next run", "app", change.AppName, "env", change.Environment, "version", change.Version)
+		return fmt.Errorf("handle %s/%s version %s: %w", change.AppName, change.Environment, change.Version, err)
+	}
+	s.recordAppChangeResult(appState, err)
+	snapshot := s.snapshotState()
+	s.mu.Unlock()
+
+	s.saveState(snapshot)
+	if err != nil {
+		return fmt.Errorf("handle %s/%s version %s: %w", change.AppName, change.Environment, change.Version, err)
+	}
+	return nil
+}
+
+// recordAppChangeResult updates the app state with the result of its handler. The caller must hold s.mu.
+func (s *Syncer) recordAppChangeResult(appState *GitOpsAppState, err error) {
+	if err != nil {
+		appState.LastError = err.Error()
+		appState.Attempts++
+		if appState.Attempts >= s.retryPolicy.MaxAttempts {
+			appState.Failed = true
+			appState.NextAttemptAt = time.Time{}
+			slog.Error("Failed to handle app change, giving up until the version changes", "app", appState.AppName, "env", appState.VersionIdentifier.env, "version", appState.CurrentVersion, "attempts", appState.Attempts, "error", err)
+		} else {
+			appState.NextAttemptAt = s.now().Add(s.retryPolicy.backoff(appState.Attempts))
+			slog.Error("Failed to handle app change, will be retried", "app", appState.AppName, "env", appState.VersionIdentifier.env, "version", appState.CurrentVersion, "attempts", appState.Attempts, "nextAttemptAt", appState.NextAttemptAt, "error", err)
+		}
+		return
+	}
+
+	appState.Handled = true
+	appState.resetRetries()
+	appState.LastHandledAt = s.now()
+	slog.Info("Handled app change", "app", appState.AppName, "env", appState.VersionIdentifier.env, "version", appState.CurrentVersion)
+}
+
+func (s *Syncer) updateUnhandledAppStatesMetric() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	unhandled := 0
+	for _, repoState := range s.state.GitOpsRepos {
+		for _, appState := range repoState.AppStates {
+			if !appState.Handled {
+				unhandled++
+			}
+		}
+	}
+	metrics.UnhandledAppStates.Set(float64(unhandled))
+}
+
+// restorePersistedAppState restores the state of a previous run, if the version did not change since then.
+// Handled versions are skipped, failed versions keep their retry bookkeeping.
+func (s *Syncer) restorePersistedAppState(repoUrl string, key AppStateKey, appState *GitOpsAppState) {
+	persistedAppState, ok := s.persistedState.findAppState(repoUrl, key)
+	if !ok || persistedAppState.CurrentVersion != appState.CurrentVersion {
+		return
+	}
+	appState.LastHandledAt = persistedAppState.LastHandledAt
+	if !persistedAppState.Handled {
+		appState.Attempts = persistedAppState.Attempts
+		appState.LastError = persistedAppState.LastError
+		appState.NextAttemptAt = persistedAppState.NextAttemptAt
+		appState.Failed = persistedAppState.Failed
+		if appState.Failed {
+			slog.Warn("Version failed in a previous run and is parked until the version changes", "app", key.AppName, "environment", key.Environment, "version", appState.CurrentVersion, "attempts", appState.Attempts)
+		}
+		return
+	}
+	appState.Handled = true
+	slog.Info("Version was already handled in a previous run", "app", key.AppName, "environment", key.Environment, "version", appState.CurrentVersion)
+}
+
+// stateSnapshot is the state to persist, numbered in the order the snapshots were taken
+type stateSnapshot struct {
+	state PersistedState
+	seq   uint64
+}
+
+// snapshotState copies the current state for persisting it. The caller must hold s.mu.
+func (s *Syncer) snapshotState() stateSnapshot {
+	persistedState := PersistedState{GitOpsRepos: make(map[string][]PersistedAppState)}
+	for repoUrl, repoState := range s.state.GitOpsRepos {
+		for key, appState := range repoState.AppStates {
+			persistedState.GitOpsRepos[repoUrl] = append(persistedState.GitOpsRepos[repoUrl], PersistedAppState{
+				AppName:        key.AppName,
+				Environment:    key.Environment,
+				CurrentVersion: appState.CurrentVersion,
+				Handled:        appState.Handled,
+				LastHandledAt:  appState.LastHandledAt,
+				Attempts:       appState.Attempts,
+				LastError:      appState.LastError,
+				NextAttemptAt:  appState.NextAttemptAt,
+				Failed:         appState.Failed,
+			})
 		}
-		appState.Handled = true
-		slog.Info("Handled app change", "app", appState.AppName, "env", appState.VersionIdentifier.env, "version", appState.CurrentVersion)
 	}
+	s.snapshotSeq++
+	return stateSnapshot{state: persistedState, seq: s.snapshotSeq}
+}
+
+// saveState writes the snapshot to the state store without holding s.mu. A snapshot older than the last saved
+// one is skipped, as concurrent handlers may save in a different order than they took their snapshots.
+// Failures are only logged, as they do not affect the current run.
+func (s *Syncer) saveState(snapshot stateSnapshot) {
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+
+	if snapshot.seq <= s.savedSeq {
+		return
+	}
+	if err := s.stateStore.Save(snapshot.state); err != nil {
+		slog.Error("Failed to persist state", "error", err)
+		return
+	}
+	s.savedSeq = snapshot.seq
+	s.persistedState = snapshot.state
 }
 
 // Gets the version of an appstate based on its VersionIdentifier
@@ -179,7 +553,7 @@ func (s *Syncer) getVersionForApp(gitopsRepo gittool.ClonedRepo, app *GitOpsAppS
 		return "", err
 	}
 
-	version, err := s.valueExtractor.ExtractValue(versionIdentifierFile, app.VersionIdentifier.yamlPath)
+	version, err := app.VersionIdentifier.extractor.ExtractValue(versionIdentifierFile, app.VersionIdentifier.yamlPath)
 	if err != nil {
 		slog.Error("Failed to extract version from file",
 			"app", app.AppName,
@@ -4,8 +4,15 @@ import (
 	"central-cyclone/internal/config"
 	"central-cyclone/internal/gittool"
 	"central-cyclone/internal/models"
+	"context"
 	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 // MockCloner implements gittool.Cloner for testing
@@ -27,6 +34,10 @@ func (m *MockCloner) CloneOrUpdateRepo(repoURL string) (gittool.ClonedRepo, erro
 	return m.CloneRepo(repoURL)
 }
 
+func (m *MockCloner) CreateRevisionWorktree(repo gittool.ClonedRepo, revision string, rules []gittool.RevisionRule) (gittool.RevisionWorktree, error) {
+	return gittool.RevisionWorktree{ClonedRepo: repo, Revision: revision}, nil
+}
+
 // MockWorkspace implements workspace.Workspace for testing
 type MockWorkspace struct {
 	readFileFromRepoCallCount int
@@ -44,6 +55,10 @@ func (m *MockWorkspace) CreateRepoFolder(repoURL string) (string, error) {
 	return "", m.createRepoFolderErr
 }
 
+func (m *MockWorkspace) CreateWorktreeFolder(repoURL string) (string, error) {
+	return "", m.createRepoFolderErr
+}
+
 func (m *MockWorkspace) SaveSbom(models.Sbom) error {
 	return nil
 }
@@ -61,11 +76,22 @@ func (m *MockWorkspace) ReadFileFromRepo(repoPath string, relativePath string) (
 	return nil, errors.New("file not found")
 }
 
+func (m *MockWorkspace) GlobFilesInRepo(repoPath string, pattern string) ([]string, error) {
+	var files []string
+	for relativePath := range m.readFileFromRepoContent {
+		if matched, _ := path.Match(pattern, relativePath); matched {
+			files = append(files, relativePath)
+		}
+	}
+	slices.Sort(files)
+	return files, nil
+}
+
 func TestNewSyncer(t *testing.T) {
 	mockCloner := &MockCloner{}
 	mockWorkspace := &MockWorkspace{}
 
-	syncer := NewSyncer(mockCloner, mockWorkspace, NoOpsAppChangedHandler{})
+	syncer := NewSyncer(mockCloner, mockWorkspace, NoOpsAppChangedHandler{}, SyncerOptions{})
 
 	if syncer == nil {
 		t.Error("NewSyncer returned nil")
@@ -101,7 +127,7 @@ func TestSyncer_Init_Success(t *testing.T) {
 		},
 	}
 
-	syncer := NewSyncer(mockCloner, mockWorkspace, NoOpsAppChangedHandler{})
+	syncer := NewSyncer(mockCloner, mockWorkspace, NoOpsAppChangedHandler{}, SyncerOptions{})
 
 	gitOpsRepos := []config.GitOpsRepo{
 		{
@@ -121,7 +147,7 @@ func TestSyncer_Init_Success(t *testing.T) {
 		},
 	}
 
-	err := syncer.Init(gitOpsRepos)
+	err := syncer.Init(context.Background(), gitOpsRepos)
 	if err != nil {
 		t.Fatalf("Init failed: %v", err)
 	}
@@ -146,6 +172,10 @@ func TestSyncer_Init_Success(t *testing.T) {
 	if mockCloner.cloneRepoCallCount != 1 {
 		t.Errorf("Expected CloneRepo to be called once, was called %d times", mockCloner.cloneRepoCallCount)
 	}
+
+	if !syncer.Ready() {
+		t.Error("Expected syncer to be ready after Init")
+	}
 }
 
 func TestSyncer_Init_MultipleRepos(t *testing.T) {
@@ -161,7 +191,7 @@ func TestSyncer_Init_MultipleRepos(t *testing.T) {
 		},
 	}
 
-	syncer := NewSyncer(mockCloner, mockWorkspace, NoOpsAppChangedHandler{})
+	syncer := NewSyncer(mockCloner, mockWorkspace, NoOpsAppChangedHandler{}, SyncerOptions{})
 
 	gitOpsRepos := []config.GitOpsRepo{
 		{
@@ -196,7 +226,7 @@ func TestSyncer_Init_MultipleRepos(t *testing.T) {
 		},
 	}
 
-	err := syncer.Init(gitOpsRepos)
+	err := syncer.Init(context.Background(), gitOpsRepos)
 	if err != nil {
 		t.Fatalf("Init failed: %v", err)
 	}
@@ -217,7 +247,7 @@ func TestSyncer_Init_CloneError(t *testing.T) {
 	}
 	mockWorkspace := &MockWorkspace{}
 
-	syncer := NewSyncer(mockCloner, mockWorkspace, NoOpsAppChangedHandler{})
+	syncer := NewSyncer(mockCloner, mockWorkspace, NoOpsAppChangedHandler{}, SyncerOptions{})
 
 	gitOpsRepos := []config.GitOpsRepo{
 		{
@@ -237,7 +267,7 @@ func TestSyncer_Init_CloneError(t *testing.T) {
 		},
 	}
 
-	err := syncer.Init(gitOpsRepos)
+	err := syncer.Init(context.Background(), gitOpsRepos)
 	if err == nil {
 		t.Error("Expected error from Init")
 	}
@@ -251,6 +281,22 @@ func TestSyncer_Init_CloneError(t *testing.T) {
 	}
 }
 
+func TestSyncer_Init_StopsWhenCancelled(t *testing.T) {
+	mockCloner := &MockCloner{}
+	syncer := NewSyncer(mockCloner, &MockWorkspace{}, NoOpsAppChangedHandler{}, SyncerOptions{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := syncer.Init(ctx, []config.GitOpsRepo{{Url: "https://github.com/example/repo.git"}})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected cancellation error, got %v", err)
+	}
+	if mockCloner.cloneRepoCallCount != 0 || syncer.Ready() {
+		t.Errorf("Expected no clone and no ready syncer after cancellation, got %d clones", mockCloner.cloneRepoCallCount)
+	}
+}
+
 func TestSyncer_Init_ReadFileError(t *testing.T) {
 	mockCloner := &MockCloner{
 		cloneRepoResult: gittool.ClonedRepo{
@@ -263,7 +309,7 @@ func TestSyncer_Init_ReadFileError(t *testing.T) {
 		readFileFromRepoErr: readFileErr,
 	}
 
-	syncer := NewSyncer(mockCloner, mockWorkspace, NoOpsAppChangedHandler{})
+	syncer := NewSyncer(mockCloner, mockWorkspace, NoOpsAppChangedHandler{}, SyncerOptions{})
 
 	gitOpsRepos := []config.GitOpsRepo{
 		{
@@ -283,7 +329,7 @@ func TestSyncer_Init_ReadFileError(t *testing.T) {
 		},
 	}
 
-	err := syncer.Init(gitOpsRepos)
+	err := syncer.Init(context.Background(), gitOpsRepos)
 	if err == nil {
 		t.Error("Expected error from Init")
 	}
@@ -306,7 +352,7 @@ func TestSyncer_Init_ExtractValueError(t *testing.T) {
 		},
 	}
 
-	syncer := NewSyncer(mockCloner, mockWorkspace, NoOpsAppChangedHandler{})
+	syncer := NewSyncer(mockCloner, mockWorkspace, NoOpsAppChangedHandler{}, SyncerOptions{})
 
 	gitOpsRepos := []config.GitOpsRepo{
 		{
@@ -326,7 +372,7 @@ func TestSyncer_Init_ExtractValueError(t *testing.T) {
 		},
 	}
 
-	err := syncer.Init(gitOpsRepos)
+	err := syncer.Init(context.Background(), gitOpsRepos)
 	if err == nil {
 		t.Error("Expected error from Init")
 	}
@@ -346,7 +392,7 @@ func TestSyncer_Init_MultipleAppsPerRepo(t *testing.T) {
 		},
 	}
 
-	syncer := NewSyncer(mockCloner, mockWorkspace, NoOpsAppChangedHandler{})
+	syncer := NewSyncer(mockCloner, mockWorkspace, NoOpsAppChangedHandler{}, SyncerOptions{})
 
 	gitOpsRepos := []config.GitOpsRepo{
 		{
@@ -381,7 +427,7 @@ func TestSyncer_Init_MultipleAppsPerRepo(t *testing.T) {
 		},
 	}
 
-	err := syncer.Init(gitOpsRepos)
+	err := syncer.Init(context.Background(), gitOpsRepos)
 	if err != nil {
 		t.Fatalf("Init failed: %v", err)
 	}
@@ -418,9 +464,9 @@ func TestSyncer_Init_EmptyRepoList(t *testing.T) {
 	mockCloner := &MockCloner{}
 	mockWorkspace := &MockWorkspace{}
 
-	syncer := NewSyncer(mockCloner, mockWorkspace, NoOpsAppChangedHandler{})
+	syncer := NewSyncer(mockCloner, mockWorkspace, NoOpsAppChangedHandler{}, SyncerOptions{})
 
-	err := syncer.Init([]config.GitOpsRepo{})
+	err := syncer.Init(context.Background(), []config.GitOpsRepo{})
 	if err != nil {
 		t.Fatalf("Init should not fail with empty list: %v", err)
 	}
@@ -443,7 +489,7 @@ func TestSyncer_Init_AppStateProperties(t *testing.T) {
 		},
 	}
 
-	syncer := NewSyncer(mockCloner, mockWorkspace, NoOpsAppChangedHandler{})
+	syncer := NewSyncer(mockCloner, mockWorkspace, NoOpsAppChangedHandler{}, SyncerOptions{})
 
 	gitOpsRepos := []config.GitOpsRepo{
 		{
@@ -463,7 +509,7 @@ func TestSyncer_Init_AppStateProperties(t *testing.T) {
 		},
 	}
 
-	err := syncer.Init(gitOpsRepos)
+	err := syncer.Init(context.Background(), gitOpsRepos)
 	if err != nil {
 		t.Fatalf("Init failed: %v", err)
 	}
@@ -487,3 +533,673 @@ func TestSyncer_Init_AppStateProperties(t *testing.T) {
 		t.Error("Expected Handled to be false initially")
 	}
 }
+
+// MockStateStore implements StateStore for testing
+type MockStateStore struct {
+	loadResult PersistedState
+	saved      []PersistedState
+}
+
+func (m *MockStateStore) Load() (PersistedState, error) {
+	return m.loadResult, nil
+}
+
+func (m *MockStateStore) Save(state PersistedState) error {
+	m.saved = append(m.saved, state)
+	return nil
+}
+
+// blockingStateStore signals every save on saving and blocks it until release is closed
+type blockingStateStore struct {
+	saving  chan struct{}
+	release chan struct{}
+	mu      sync.Mutex
+	saved   []PersistedState
+}
+
+func (b *blockingStateStore) Load() (PersistedState, error) {
+	return PersistedState{}, nil
+}
+
+func (b *blockingStateStore) Save(state PersistedState) error {
+	b.saving <- struct{}{}
+	<-b.release
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.saved = append(b.saved, state)
+	return nil
+}
+
+func TestSyncer_HandleAppChange_SavesStateWithoutBlockingStatus(t *testing.T) {
+	repoUrl := "https://github.com/example/repo.git"
+	stateStore := &blockingStateStore{saving: make(chan struct{}, 2), release: make(chan struct{})}
+	syncer := NewSyncer(&MockCloner{}, &MockWorkspace{}, NoOpsAppChangedHandler{}, SyncerOptions{StateStore: stateStore, MaxConcurrency: 2})
+	syncer.state.GitOpsRepos[repoUrl] = &GitOpsRepoState{
+		AppStates: map[AppStateKey]*GitOpsAppState{
+			{AppName: "app1", Environment: "prod"}: {AppName: "app1", VersionIdentifier: VersionIdentifier{env: "prod"}, CurrentVersion: "1.0.0"},
+			{AppName: "app2", Environment: "prod"}: {AppName: "app2", VersionIdentifier: VersionIdentifier{env: "prod"}, CurrentVersion: "2.0.0"},
+		},
+	}
+
+	done := make(chan error)
+	go func() { done <- syncer.checkUnhandledChanges(context.Background(), "") }()
+	<-stateStore.saving
+
+	status := make(chan StatusReport)
+	go func() { status <- syncer.Status() }()
+	select {
+	case <-status:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the status not to wait for the state store")
+	}
+
+	close(stateStore.release)
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	last := stateStore.saved[len(stateStore.saved)-1]
+	for _, app := range last.GitOpsRepos[repoUrl] {
+		if !app.Handled {
+			t.Errorf("expected the last saved state to contain all handled apps, got %+v", last)
+		}
+	}
+}
+
+func TestSyncer_Init_SkipsVersionsHandledBefore(t *testing.T) {
+	repoUrl := "https://github.com/example/repo.git"
+	mockCloner := &MockCloner{
+		cloneRepoResult: gittool.ClonedRepo{Path: "/tmp/repo", RepoUrl: repoUrl},
+	}
+	mockWorkspace := &MockWorkspace{
+		readFileFromRepoContent: map[string][]byte{
+			"prod.yaml":    []byte("version: 1.0.0"),
+			"staging.yaml": []byte("version: 1.1.0"),
+		},
+	}
+	stateStore := &MockStateStore{
+		loadResult: PersistedState{
+			GitOpsRepos: map[string][]PersistedAppState{
+				repoUrl: {
+					{AppName: "app1", Environment: "prod", CurrentVersion: "1.0.0", Handled: true},
+					{AppName: "app1", Environment: "staging", CurrentVersion: "1.0.0", Handled: true},
+				},
+			},
+		},
+	}
+
+	syncer := NewSyncer(mockCloner, mockWorkspace, NoOpsAppChangedHandler{}, SyncerOptions{StateStore: stateStore})
+
+	err := syncer.Init(context.Background(), []config.GitOpsRepo{
+		{
+			Url: repoUrl,
+			GitOpsApplications: []config.GitOpsApplication{
+				{
+					ApplicationName: "app1",
+					VersionIdentifiers: []config.VersionIdentifier{
+						{Environment: "prod", Filepath: "prod.yaml", YamlPath: ".version"},
+						{Environment: "staging", Filepath: "staging.yaml", YamlPath: ".version"},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+
+	appStates := syncer.state.GitOpsRepos[repoUrl].AppStates
+	if !appStates[AppStateKey{AppName: "app1", Environment: "prod"}].Handled {
+		t.Error("Expected unchanged version to be marked as handled")
+	}
+	if appStates[AppStateKey{AppName: "app1", Environment: "staging"}].Handled {
+		t.Error("Expected changed version to be unhandled")
+	}
+
+	syncer.checkUnhandledChanges(context.Background(), "")
+
+	if len(stateStore.saved) != 1 {
+		t.Fatalf("Expected state to be saved once, was saved %d times", len(stateStore.saved))
+	}
+	persisted, ok := stateStore.saved[0].findAppState(repoUrl, AppStateKey{AppName: "app1", Environment: "staging"})
+	if !ok || !persisted.Handled || persisted.CurrentVersion != "1.1.0" {
+		t.Errorf("Expected handled staging version to be persisted, got %+v", persisted)
+	}
+}
+
+// concurrencyTrackingHandler records how many app changes are handled at the same time
+type concurrencyTrackingHandler struct {
+	mu            sync.Mutex
+	running       int
+	maxRunning    int
+	handledApps   []string
+	handleTimeout time.Duration
+}
+
+func (h *concurrencyTrackingHandler) HandleAppChange(ctx context.Context, change AppChange) error {
+	h.mu.Lock()
+	h.running++
+	h.maxRunning = max(h.maxRunning, h.running)
+	h.mu.Unlock()
+
+	time.Sleep(h.handleTimeout)
+
+	h.mu.Lock()
+	h.running--
+	h.handledApps = append(h.handledApps, change.AppName)
+	h.mu.Unlock()
+	return nil
+}
+
+func TestSyncer_CheckUnhandledChanges_RespectsMaxConcurrency(t *testing.T) {
+	repoUrl := "https://github.com/example/repo.git"
+	handler := &concurrencyTrackingHandler{handleTimeout: 20 * time.Millisecond}
+	syncer := NewSyncer(&MockCloner{}, &MockWorkspace{}, handler, SyncerOptions{MaxConcurrency: 2})
+
+	appStates := make(map[AppStateKey]*GitOpsAppState)
+	for i := range 6 {
+		appName := fmt.Sprintf("app%d", i)
+		appStates[AppStateKey{AppName: appName, Environment: "prod"}] = &GitOpsAppState{
+			AppName:           appName,
+			VersionIdentifier: VersionIdentifier{env: "prod"},
+			CurrentVersion:    "1.0.0",
+		}
+	}
+	syncer.state.GitOpsRepos[repoUrl] = &GitOpsRepoState{AppStates: appStates}
+
+	syncer.checkUnhandledChanges(context.Background(), "")
+
+	if len(handler.handledApps) != 6 {
+		t.Fatalf("Expected 6 handled apps, got %d", len(handler.handledApps))
+	}
+	if handler.maxRunning > 2 {
+		t.Errorf("Expected at most 2 concurrent handlers, got %d", handler.maxRunning)
+	}
+	for _, appState := range appStates {
+		if !appState.Handled {
+			t.Errorf("Expected %s to be handled", appState.AppName)
+		}
+	}
+}
+
+func TestSyncer_CheckUnhandledChanges_StopsWhenCancelled(t *testing.T) {
+	repoUrl := "https://github.com/example/repo.git"
+	handler := &concurrencyTrackingHandler{}
+	syncer := NewSyncer(&MockCloner{}, &MockWorkspace{}, handler, SyncerOptions{})
+	syncer.state.GitOpsRepos[repoUrl] = &GitOpsRepoState{
+		AppStates: map[AppStateKey]*GitOpsAppState{
+			{AppName: "app1", Environment: "prod"}: {AppName: "app1", VersionIdentifier: VersionIdentifier{env: "prod"}, CurrentVersion: "1.0.0"},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := syncer.checkUnhandledChanges(ctx, "")
+
+	if len(handler.handledApps) != 0 {
+		t.Errorf("Expected no app changes to be handled after cancellation, got %d", len(handler.handledApps))
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected cancellation error, got %v", err)
+	}
+}
+
+// recordingHandler records the handled app changes
+type recordingHandler struct {
+	mu      sync.Mutex
+	changes []AppChange
+}
+
+func (h *recordingHandler) HandleAppChange(ctx context.Context, change AppChange) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.changes = append(h.changes, change)
+	return nil
+}
+
+func TestSyncer_CheckUnhandledChanges_PassesGitOpsProvenance(t *testing.T) {
+	repoUrl := "https://github.com/example/repo.git"
+	handler := &recordingHandler{}
+	syncer := NewSyncer(&MockCloner{}, &MockWorkspace{}, handler, SyncerOptions{})
+	syncer.state.GitOpsRepos[repoUrl] = &GitOpsRepoState{
+		AppStates: map[AppStateKey]*GitOpsAppState{
+			{AppName: "app1", Environment: "prod"}: {AppName: "app1", VersionIdentifier: VersionIdentifier{env: "prod"}, CurrentVersion: "1.0.0", GitOpsCommit: "abc123"},
+		},
+	}
+
+	if err := syncer.checkUnhandledChanges(context.Background(), ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := AppChange{AppName: "app1", Environment: "prod", Version: "1.0.0", GitOpsRepoUrl: repoUrl, GitOpsCommit: "abc123"}
+	if len(handler.changes) != 1 || handler.changes[0] != want {
+		t.Errorf("expected change %+v, got %+v", want, handler.changes)
+	}
+}
+
+// cancellingHandler cancels the context while handling an app change, like a shutdown during an analysis
+type cancellingHandler struct {
+	cancel context.CancelFunc
+}
+
+func (h cancellingHandler) HandleAppChange(ctx context.Context, change AppChange) error {
+	h.cancel()
+	return ctx.Err()
+}
+
+func TestSyncer_CheckUnhandledChanges_CancelledChangeIsNoAttempt(t *testing.T) {
+	repoUrl := "https://github.com/example/repo.git"
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	syncer := NewSyncer(&MockCloner{}, &MockWorkspace{}, cancellingHandler{cancel: cancel}, SyncerOptions{})
+	appState := &GitOpsAppState{AppName: "app1", VersionIdentifier: VersionIdentifier{env: "prod"}, CurrentVersion: "1.0.0"}
+	syncer.state.GitOpsRepos[repoUrl] = &GitOpsRepoState{
+		AppStates: map[AppStateKey]*GitOpsAppState{{AppName: "app1", Environment: "prod"}: appState},
+	}
+
+	err := syncer.checkUnhandledChanges(ctx, "")
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected cancellation error, got %v", err)
+	}
+	if appState.Handled || appState.Attempts != 0 || !appState.NextAttemptAt.IsZero() || appState.LastError != "" {
+		t.Errorf("Expected cancelled change to be handled on the next run without backoff, got %+v", appState)
+	}
+}
+
+func TestSyncer_CheckUnhandledChanges_ReturnsFailedAppChanges(t *testing.T) {
+	repoUrl := "https://github.com/example/repo.git"
+	handlerErr := errors.New("cdxgen failed")
+	syncer := NewSyncer(&MockCloner{}, &MockWorkspace{}, failingHandler{err: handlerErr}, SyncerOptions{MaxConcurrency: 2})
+	syncer.state.GitOpsRepos[repoUrl] = &GitOpsRepoState{
+		AppStates: map[AppStateKey]*GitOpsAppState{
+			{AppName: "app1", Environment: "prod"}: {AppName: "app1", VersionIdentifier: VersionIdentifier{env: "prod"}, CurrentVersion: "1.0.0"},
+			{AppName: "app2", Environment: "prod"}: {AppName: "app2", VersionIdentifier: VersionIdentifier{env: "prod"}, CurrentVersion: "2.0.0"},
+			{AppName: "app3", Environment: "prod"}: {AppName: "app3", VersionIdentifier: VersionIdentifier{env: "prod"}, CurrentVersion: "3.0.0", Handled: true},
+		},
+	}
+
+	err := syncer.checkUnhandledChanges(context.Background(), "")
+
+	if !errors.Is(err, handlerErr) {
+		t.Fatalf("Expected handler error to be returned, got %v", err)
+	}
+	for _, app := range []string{"app1/prod version 1.0.0", "app2/prod version 2.0.0"} {
+		if !strings.Contains(err.Error(), app) {
+			t.Errorf("Expected error to mention %q, got %v", app, err)
+		}
+	}
+	if strings.Contains(err.Error(), "app3") {
+		t.Errorf("Expected handled app not to be part of the error, got %v", err)
+	}
+
+	if err := syncer.checkUnhandledChanges(context.Background(), ""); err != nil {
+		t.Errorf("Expected no error while failed changes are backed off, got %v", err)
+	}
+}
+
+// failingHandler fails every app change with the given error
+type failingHandler struct {
+	err error
+}
+
+func (h failingHandler) HandleAppChange(ctx context.Context, change AppChange) error {
+	return h.err
+}
+
+func TestSyncer_Status_ReportsHandledAndFailedApps(t *testing.T) {
+	repoUrl := "https://github.com/example/repo.git"
+	syncer := NewSyncer(&MockCloner{}, &MockWorkspace{}, failingHandler{err: errors.New("cdxgen failed")}, SyncerOptions{})
+	syncer.state.GitOpsRepos[repoUrl] = &GitOpsRepoState{
+		AppStates: map[AppStateKey]*GitOpsAppState{
+			{AppName: "app2", Environment: "prod"}: {AppName: "app2", VersionIdentifier: VersionIdentifier{env: "prod"}, CurrentVersion: "2.0.0"},
+			{AppName: "app1", Environment: "prod"}: {AppName: "app1", VersionIdentifier: VersionIdentifier{env: "prod"}, CurrentVersion: "1.0.0", Handled: true, LastHandledAt: time.Now()},
+		},
+	}
+
+	syncer.checkUnhandledChanges(context.Background(), "")
+	report := syncer.Status()
+
+	if report.Ready {
+		t.Error("Expected syncer not to be ready before Init")
+	}
+	if len(report.GitOpsRepos) != 1 || len(report.GitOpsRepos[0].Apps) != 2 {
+		t.Fatalf("Unexpected report: %+v", report)
+	}
+
+	handledApp, failedApp := report.GitOpsRepos[0].Apps[0], report.GitOpsRepos[0].Apps[1]
+	if handledApp.AppName != "app1" || !handledApp.Handled || handledApp.LastHandledAt == nil {
+		t.Errorf("Unexpected status of handled app: %+v", handledApp)
+	}
+	if failedApp.AppName != "app2" || failedApp.Handled || failedApp.LastError != "cdxgen failed" {
+		t.Errorf("Unexpected status of failed app: %+v", failedApp)
+	}
+}
+
+// countingFailingHandler fails every app change and counts the attempts
+type countingFailingHandler struct {
+	mu       sync.Mutex
+	attempts int
+}
+
+func (h *countingFailingHandler) HandleAppChange(ctx context.Context, change AppChange) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.attempts++
+	return errors.New("cdxgen failed")
+}
+
+func TestSyncer_CheckUnhandledChanges_RetriesWithBackoffUntilParked(t *testing.T) {
+	repoUrl := "https://github.com/example/repo.git"
+	handler := &countingFailingHandler{}
+	stateStore := &MockStateStore{}
+	syncer := NewSyncer(&MockCloner{}, &MockWorkspace{}, handler, SyncerOptions{
+		StateStore:  stateStore,
+		RetryPolicy: RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Minute, MaxBackoff: time.Hour},
+	})
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	syncer.now = func() time.Time { return now }
+	key := AppStateKey{AppName: "app1", Environment: "prod"}
+	appState := &GitOpsAppState{AppName: "app1", VersionIdentifier: VersionIdentifier{env: "prod"}, CurrentVersion: "1.0.0"}
+	syncer.state.GitOpsRepos[repoUrl] = &GitOpsRepoState{AppStates: map[AppStateKey]*GitOpsAppState{key: appState}}
+
+	syncer.checkUnhandledChanges(context.Background(), "")
+	if handler.attempts != 1 || appState.Attempts != 1 || !appState.NextAttemptAt.Equal(now.Add(time.Minute)) {
+		t.Fatalf("Expected first attempt with one minute backoff, got %+v", appState)
+	}
+
+	syncer.checkUnhandledChanges(context.Background(), "")
+	if handler.attempts != 1 {
+		t.Fatalf("Expected no attempt during backoff, got %d attempts", handler.attempts)
+	}
+
+	now = now.Add(time.Minute)
+	syncer.checkUnhandledChanges(context.Background(), "")
+	if handler.attempts != 2 || !appState.NextAttemptAt.Equal(now.Add(2*time.Minute)) {
+		t.Fatalf("Expected second attempt with doubled backoff, got %+v", appState)
+	}
+
+	now = now.Add(2 * time.Minute)
+	syncer.checkUnhandledChanges(context.Background(), "")
+	if handler.attempts != 3 || !appState.Failed {
+		t.Fatalf("Expected app state to be parked after max attempts, got %+v", appState)
+	}
+
+	now = now.Add(24 * time.Hour)
+	syncer.checkUnhandledChanges(context.Background(), "")
+	if handler.attempts != 3 {
+		t.Errorf("Expected no attempt for parked app state, got %d attempts", handler.attempts)
+	}
+
+	persisted, ok := stateStore.saved[len(stateStore.saved)-1].findAppState(repoUrl, key)
+	if !ok || !persisted.Failed || persisted.Attempts != 3 || persisted.LastError != "cdxgen failed" {
+		t.Errorf("Expected parked app state to be persisted, got %+v", persisted)
+	}
+
+	report := syncer.Status()
+	appStatus := report.GitOpsRepos[0].Apps[0]
+	if !appStatus.Failed || appStatus.Attempts != 3 || appStatus.NextAttemptAt != nil {
+		t.Errorf("Expected parked app state in status, got %+v", appStatus)
+	}
+}
+
+func TestSyncer_ReconcileOnce_RetriesBackoffAndFailsForParkedVersions(t *testing.T) {
+	repoUrl := "https://github.com/example/repo.git"
+	handler := &recordingHandler{}
+	syncer := NewSyncer(&MockCloner{}, &MockWorkspace{}, handler, SyncerOptions{})
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	syncer.now = func() time.Time { return now }
+	backedOff := &GitOpsAppState{AppName: "app1", VersionIdentifier: VersionIdentifier{env: "prod"}, CurrentVersion: "1.0.0", Attempts: 1, NextAttemptAt: now.Add(time.Hour)}
+	syncer.state.GitOpsRepos[repoUrl] = &GitOpsRepoState{
+		Repo: gittool.ClonedRepo{Path: t.TempDir(), RepoUrl: repoUrl},
+		AppStates: map[AppStateKey]*GitOpsAppState{
+			{AppName: "app1", Environment: "prod"}: backedOff,
+			{AppName: "app2", Environment: "prod"}: {AppName: "app2", VersionIdentifier: VersionIdentifier{env: "prod"}, CurrentVersion: "2.0.0", Attempts: 5, LastError: "cdxgen failed", Failed: true},
+			{AppName: "app3", Environment: "prod"}: {AppName: "app3", VersionIdentifier: VersionIdentifier{env: "prod"}, CurrentVersion: "3.0.0", Handled: true},
+		},
+	}
+
+	err := syncer.ReconcileOnce(context.Background())
+
+	if len(handler.changes) != 1 || handler.changes[0].AppName != "app1" {
+		t.Errorf("Expected only the backed off change to be handled, got %+v", handler.changes)
+	}
+	if !backedOff.Handled {
+		t.Errorf("Expected backed off change to be handled, got %+v", backedOff)
+	}
+	if err == nil || !strings.Contains(err.Error(), "app2/prod version 2.0.0: parked after 5 failed attempts: cdxgen failed") {
+		t.Errorf("Expected error for the parked version, got %v", err)
+	}
+	if err != nil && (strings.Contains(err.Error(), "app1") || strings.Contains(err.Error(), "app3")) {
+		t.Errorf("Expected handled apps not to be part of the error, got %v", err)
+	}
+}
+
+func TestSyncer_ReconcileAll_HandlesBackedOffAndParkedVersions(t *testing.T) {
+	repoUrl := "https://github.com/example/repo.git"
+	handler := &recordingHandler{}
+	syncer := NewSyncer(&MockCloner{}, &MockWorkspace{}, handler, SyncerOptions{})
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	syncer.now = func() time.Time { return now }
+	syncer.state.GitOpsRepos[repoUrl] = &GitOpsRepoState{
+		Repo: gittool.ClonedRepo{Path: t.TempDir(), RepoUrl: repoUrl},
+		AppStates: map[AppStateKey]*GitOpsAppState{
+			{AppName: "app1", Environment: "prod"}: {AppName: "app1", VersionIdentifier: VersionIdentifier{env: "prod"}, CurrentVersion: "1.0.0", Attempts: 1, NextAttemptAt: now.Add(time.Hour)},
+			{AppName: "app2", Environment: "prod"}: {AppName: "app2", VersionIdentifier: VersionIdentifier{env: "prod"}, CurrentVersion: "2.0.0", Attempts: 5, Failed: true},
+			{AppName: "app3", Environment: "prod"}: {AppName: "app3", VersionIdentifier: VersionIdentifier{env: "prod"}, CurrentVersion: "3.0.0", Handled: true},
+		},
+	}
+
+	report := syncer.Status()
+	retryStates := []string{}
+	for _, app := range report.GitOpsRepos[0].Apps {
+		retryStates = append(retryStates, app.RetryState())
+	}
+	if !slices.Equal(retryStates, []string{"backoff until 2024-01-01T13:00:00Z", "parked after 5 attempts", ""}) {
+		t.Errorf("unexpected retry states %q", retryStates)
+	}
+
+	syncer.ReconcileAll(context.Background())
+
+	handledApps := []string{}
+	for _, change := range handler.changes {
+		handledApps = append(handledApps, change.AppName)
+	}
+	slices.Sort(handledApps)
+	if !slices.Equal(handledApps, []string{"app1", "app2"}) {
+		t.Errorf("expected backed off and parked versions to be handled, got %v", handledApps)
+	}
+}
+
+func TestSyncer_Init_RestoresRetryStateOfUnchangedVersion(t *testing.T) {
+	repoUrl := "https://github.com/example/repo.git"
+	mockCloner := &MockCloner{
+		cloneRepoResult: gittool.ClonedRepo{Path: "/tmp/repo", RepoUrl: repoUrl},
+	}
+	mockWorkspace := &MockWorkspace{
+		readFileFromRepoContent: map[string][]byte{
+			"prod.yaml":    []byte("version: 1.0.0"),
+			"staging.yaml": []byte("version: 1.1.0"),
+		},
+	}
+	stateStore := &MockStateStore{
+		loadResult: PersistedState{
+			GitOpsRepos: map[string][]PersistedAppState{
+				repoUrl: {
+					{AppName: "app1", Environment: "prod", CurrentVersion: "1.0.0", Attempts: 5, LastError: "cdxgen failed", Failed: true},
+					{AppName: "app1", Environment: "staging", CurrentVersion: "1.0.0", Attempts: 5, LastError: "cdxgen failed", Failed: true},
+				},
+			},
+		},
+	}
+
+	syncer := NewSyncer(mockCloner, mockWorkspace, NoOpsAppChangedHandler{}, SyncerOptions{StateStore: stateStore})
+
+	err := syncer.Init(context.Background(), []config.GitOpsRepo{
+		{
+			Url: repoUrl,
+			GitOpsApplications: []config.GitOpsApplication{
+				{
+					ApplicationName: "app1",
+					VersionIdentifiers: []config.VersionIdentifier{
+						{Environment: "prod", Filepath: "prod.yaml", YamlPath: ".version"},
+						{Environment: "staging", Filepath: "staging.yaml", YamlPath: ".version"},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+
+	appStates := syncer.state.GitOpsRepos[repoUrl].AppStates
+	prod := appStates[AppStateKey{AppName: "app1", Environment: "prod"}]
+	if !prod.Failed || prod.Attempts != 5 || prod.LastError != "cdxgen failed" {
+		t.Errorf("Expected failed unchanged version to stay parked, got %+v", prod)
+	}
+	staging := appStates[AppStateKey{AppName: "app1", Environment: "staging"}]
+	if staging.Failed || staging.Attempts != 0 {
+		t.Errorf("Expected changed version to be retried, got %+v", staging)
+	}
+}
+
+func TestSyncer_Init_TypedVersionIdentifiers(t *testing.T) {
+	repoUrl := "https://github.com/example/repo.git"
+	mockCloner := &MockCloner{
+		cloneRepoResult: gittool.ClonedRepo{Path: "/tmp/repo", RepoUrl: repoUrl},
+	}
+	mockWorkspace := &MockWorkspace{
+		readFileFromRepoContent: map[string][]byte{
+			"kustomization.yaml": []byte("images:\n  - name: ghcr.io/org/app\n    newTag: 1.2.3"),
+			"application.yaml":   []byte("kind: Application\nspec:\n  source:\n    targetRevision: v2.0.0"),
+			"helmrelease.yaml":   []byte("kind: HelmRelease\nspec:\n  chart:\n    spec:\n      version: 0.3.0"),
+		},
+	}
+
+	syncer := NewSyncer(mockCloner, mockWorkspace, NoOpsAppChangedHandler{}, SyncerOptions{})
+
+	err := syncer.Init(context.Background(), []config.GitOpsRepo{
+		{
+			Url: repoUrl,
+			GitOpsApplications: []config.GitOpsApplication{
+				{
+					ApplicationName: "app1",
+					VersionIdentifiers: []config.VersionIdentifier{
+						{Environment: "dev", Filepath: "kustomization.yaml", Kind: config.VersionIdentifierKindKustomizeImage, ImageName: "ghcr.io/org/app"},
+						{Environment: "staging", Filepath: "application.yaml", Kind: config.VersionIdentifierKindArgoApplication},
+						{Environment: "prod", Filepath: "helmrelease.yaml", Kind: config.VersionIdentifierKindHelmRelease},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+
+	expected := map[string]string{"dev": "1.2.3", "staging": "v2.0.0", "prod": "0.3.0"}
+	for env, version := range expected {
+		appState := syncer.state.GitOpsRepos[repoUrl].AppStates[AppStateKey{AppName: "app1", Environment: env}]
+		if appState.CurrentVersion != version {
+			t.Errorf("Expected version %q for %s, got %q", version, env, appState.CurrentVersion)
+		}
+	}
+}
+
+// caseInsensitiveResolver pairs discovered environments with the given project environments, ignoring case
+type caseInsensitiveResolver struct {
+	environments []string
+}
+
+func (r caseInsensitiveResolver) ResolveEnvironment(applicationName, discoveredEnvironment string) (string, bool) {
+	for _, environment := range r.environments {
+		if strings.EqualFold(environment, discoveredEnvironment) {
+			return environment, true
+		}
+	}
+	return "", false
+}
+
+func TestSyncer_Init_DiscoversTemplatedEnvironments(t *testing.T) {
+	repoUrl := "https://github.com/example/repo.git"
+	mockCloner := &MockCloner{
+		cloneRepoResult: gittool.ClonedRepo{Path: "/tmp/repo", RepoUrl: repoUrl},
+	}
+	mockWorkspace := &MockWorkspace{
+		readFileFromRepoContent: map[string][]byte{
+			"apps/basket/dev/values.yaml":     []byte("version: 1.0.0"),
+			"apps/basket/staging/values.yaml": []byte("version: 1.1.0"),
+			"apps/basket/base/values.yaml":    []byte("version: 0.0.0"),
+			"apps/basket/prod/values.yaml":    []byte("version: 0.9.0"),
+			"overrides/prod.yaml":             []byte("version: 1.2.0"),
+		},
+	}
+
+	syncer := NewSyncer(mockCloner, mockWorkspace, NoOpsAppChangedHandler{}, SyncerOptions{
+		EnvironmentResolver: caseInsensitiveResolver{environments: []string{"Dev", "Staging", "Prod"}},
+	})
+
+	err := syncer.Init(context.Background(), []config.GitOpsRepo{
+		{
+			Url: repoUrl,
+			GitOpsApplications: []config.GitOpsApplication{
+				{
+					ApplicationName: "basket",
+					VersionIdentifiers: []config.VersionIdentifier{
+						{Filepath: "apps/basket/{env}/values.yaml", YamlPath: ".version"},
+						{Environment: "Prod", Filepath: "overrides/prod.yaml", YamlPath: ".version"},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+
+	appStates := syncer.state.GitOpsRepos[repoUrl].AppStates
+	if len(appStates) != 3 {
+		t.Fatalf("Expected 3 app states, got %d", len(appStates))
+	}
+	expected := map[string]string{"Dev": "1.0.0", "Staging": "1.1.0", "Prod": "1.2.0"}
+	for env, version := range expected {
+		appState, ok := appStates[AppStateKey{AppName: "basket", Environment: env}]
+		if !ok {
+			t.Errorf("Expected app state for environment %s", env)
+			continue
+		}
+		if appState.CurrentVersion != version {
+			t.Errorf("Expected version %q for %s, got %q", version, env, appState.CurrentVersion)
+		}
+	}
+}
+
+func TestTemplateMatcher(t *testing.T) {
+	tests := []struct {
+		template string
+		path     string
+		wantEnv  string
+	}{
+		{template: "apps/basket/{env}/values.yaml", path: "apps/basket/dev/values.yaml", wantEnv: "dev"},
+		{template: "apps/basket/{env}/values.yaml", path: "apps/basket/dev/nested/values.yaml", wantEnv: ""},
+		{template: "apps/*/values-{env}.yaml", path: "apps/basket/values-prod.yaml", wantEnv: "prod"},
+		{template: "apps/basket.v2/{env}.yaml", path: "apps/basketxv2/dev.yaml", wantEnv: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			matcher, err := templateMatcher(tt.template)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			env := ""
+			if match := matcher.FindStringSubmatch(tt.path); match != nil {
+				env = match[1]
+			}
+			if env != tt.wantEnv {
+				t.Errorf("expected environment %q, got %q", tt.wantEnv, env)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package gitops
+
+import (
+	"central-cyclone/internal/config"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// EnvironmentResolver pairs an environment discovered by a templated version identifier
+// with the environment of a DependencyTrack project of the application
+type EnvironmentResolver interface {
+	ResolveEnvironment(applicationName, discoveredEnvironment string) (string, bool)
+}
+
+// identityEnvironmentResolver uses discovered environments as they are
+type identityEnvironmentResolver struct{}
+
+func (identityEnvironmentResolver) ResolveEnvironment(applicationName, discoveredEnvironment string) (string, bool) {
+	return discoveredEnvironment, true
+}
+
+// expandVersionIdentifiers replaces templated version identifiers with one identifier per discovered environment.
+// Explicitly configured environments take precedence over discovered ones.
+func (s *Syncer) expandVersionIdentifiers(repoPath string, app config.GitOpsApplication) ([]config.VersionIdentifier, error) {
+	var expanded []config.VersionIdentifier
+	environments := make(map[string]bool)
+
+	for _, versionIdentifier := range app.VersionIdentifiers {
+		if !versionIdentifier.IsTemplated() {
+			expanded = append(expanded, versionIdentifier)
+			environments[versionIdentifier.Environment] = true
+		}
+	}
+
+	for _, versionIdentifier := range app.VersionIdentifiers {
+		if !versionIdentifier.IsTemplated() {
+			continue
+		}
+
+		discovered, err := s.discoverEnvironments(repoPath, versionIdentifier.Filepath)
+		if err != nil {
+			return nil, err
+		}
+		if len(discovered) == 0 {
+			slog.Warn("No files found for templated version identifier", "app", app.ApplicationName, "filepath", versionIdentifier.Filepath)
+		}
+
+		for _, environmentFile := range discovered {
+			environment, ok := s.environmentResolver.ResolveEnvironment(app.ApplicationName, environmentFile.environment)
+			if !ok {
+				slog.Warn("Discovered environment has no matching project, skipping",
+					"app", app.ApplicationName,
+					"environment", environmentFile.environment,
+					"filepath", environmentFile.filePath)
+				continue
+			}
+			if environments[environment] {
+				slog.Debug("Environment is already configured, skipping discovered one", "app", app.ApplicationName, "environment", environment, "filepath", environmentFile.filePath)
+				continue
+			}
+			environments[environment] = true
+
+			concreteIdentifier := versionIdentifier
+			concreteIdentifier.Environment = environment
+			concreteIdentifier.Filepath = environmentFile.filePath
+			expanded = append(expanded, concreteIdentifier)
+			slog.Info("Discovered environment", "app", app.ApplicationName, "environment", environment, "filepath", environmentFile.filePath)
+		}
+	}
+
+	return expanded, nil
+}
+
+type environmentFile struct {
+	environment string
+	filePath    string
+}
+
+// discoverEnvironments finds all files matching the templated filepath and extracts the environment of each.
+// The placeholder matches a single path segment, further glob wildcards are supported.
+func (s *Syncer) discoverEnvironments(repoPath string, templatedPath string) ([]environmentFile, error) {
+	globPattern := strings.ReplaceAll(templatedPath, config.EnvironmentPlaceholder, "*")
+	files, err := s.workspace.GlobFilesInRepo(repoPath, globPattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover environments of %s: %w", templatedPath, err)
+	}
+
+	matcher, err := templateMatcher(templatedPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var environmentFiles []environmentFile
+	for _, file := range files {
+		match := matcher.FindStringSubmatch(file)
+		if match == nil || match[1] == "" {
+			continue
+		}
+		environmentFiles = append(environmentFiles, environmentFile{environment: match[1], filePath: file})
+	}
+	return environmentFiles, nil
+}
+
+// templateMatcher converts a templated glob path into a regular expression capturing the environment
+func templateMatcher(templatedPath string) (*regexp.Regexp, error) {
+	var pattern strings.Builder
+	pattern.WriteString("^")
+	for i, part := range strings.Split(templatedPath, config.EnvironmentPlaceholder) {
+		if i > 0 {
+			pattern.WriteString("([^/]+)")
+		}
+		for _, r := range part {
+			switch r {
+			case '*':
+				pattern.WriteString("[^/]*")
+			case '?':
+				pattern.WriteString("[^/]")
+			default:
+				pattern.WriteString(regexp.QuoteMeta(string(r)))
+			}
+		}
+	}
+	pattern.WriteString("$")
+
+	matcher, err := regexp.Compile(pattern.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid templated filepath %s: %w", templatedPath, err)
+	}
+	return matcher, nil
+}
@@ -1,11 +1,13 @@
 package gittool
 
 import (
+	"central-cyclone/internal/metrics"
 	"central-cyclone/internal/workspace"
 	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/go-git/go-git/v6"
 	"github.com/go-git/go-git/v6/plumbing/transport"
@@ -15,6 +17,7 @@ import (
 type Cloner interface {
 	CloneRepo(repoURL string) (ClonedRepo, error)
 	CloneOrUpdateRepo(repoURL string) (ClonedRepo, error)
+	CreateRevisionWorktree(repo ClonedRepo, revision string, rules []RevisionRule) (RevisionWorktree, error)
 }
 
 func CreateLocalGitCloner(workspaceHandler workspace.Workspace) Cloner {
@@ -46,7 +49,9 @@ func (c LocalGitCloner) CloneRepo(repoURL string) (ClonedRepo, error) {
 		}
 	}
 
+	start := time.Now()
 	_, err = git.PlainClone(path, cloneOpts)
+	metrics.GitDuration.WithLabelValues("clone", metrics.Result(err)).Observe(time.Since(start).Seconds())
 	if err != nil {
 		return ClonedRepo{}, err
 	}
@@ -82,11 +87,17 @@ func (c LocalGitCloner) CloneOrUpdateRepo(repoURL string) (ClonedRepo, error) {
 		}
 
 		// Fetch updates from the remote (updates all tags and remote branches)
+		start := time.Now()
 		err = repo.Fetch(&git.FetchOptions{
 			Auth:  auth,
 			Force: true,        // Ensures tags are updated/overwritten if changed on remote
 			Tags:  git.AllTags, // Explicitly pull down all tags
 		})
+		fetchErr := err
+		if err == git.NoErrAlreadyUpToDate {
+			fetchErr = nil
+		}
+		metrics.GitDuration.WithLabelValues("fetch", metrics.Result(fetchErr)).Observe(time.Since(start).Seconds())
 
 		// git.NoErrAlreadyUpToDate means there was nothing new to download, which is perfectly fine!
 		if err != nil && err != git.NoErrAlreadyUpToDate {
@@ -107,3 +118,20 @@ func (c LocalGitCloner) CloneOrUpdateRepo(repoURL string) (ClonedRepo, error) {
 		return clonedRepo, nil
 	}
 }
+
+// CreateRevisionWorktree checks out the given revision of repo into its own folder within the workspace
+func (c LocalGitCloner) CreateRevisionWorktree(repo ClonedRepo, revision string, rules []RevisionRule) (RevisionWorktree, error) {
+	path, err := c.workspace.CreateWorktreeFolder(repo.RepoUrl)
+	if err != nil {
+		return RevisionWorktree{}, err
+	}
+
+	worktree, err := repo.CreateRevisionWorktree(revision, path, rules)
+	if err != nil {
+		if removeErr := os.RemoveAll(path); removeErr != nil {
+			slog.Warn("Could not remove worktree folder", "path", path, "error", removeErr)
+		}
+		return RevisionWorktree{}, err
+	}
+	return worktree, nil
+}
@@ -23,6 +23,10 @@ func (m *MockWorkspace) CreateRepoFolder(repoURL string) (string, error) {
 	return m.createRepoFolderPath, nil
 }
 
+func (m *MockWorkspace) CreateWorktreeFolder(repoURL string) (string, error) {
+	return m.createRepoFolderPath, m.createRepoFolderErr
+}
+
 func (m *MockWorkspace) Clear() error {
 	m.clearCalls++
 	return m.clearErr
@@ -36,6 +40,10 @@ func (m *MockWorkspace) ReadFileFromRepo(repoPath string, relativePath string) (
 	return nil, nil
 }
 
+func (m *MockWorkspace) GlobFilesInRepo(repoPath string, pattern string) ([]string, error) {
+	return nil, nil
+}
+
 func TestCreateLocalGitCloner(t *testing.T) {
 	mockWS := &MockWorkspace{}
 	cloner := CreateLocalGitCloner(mockWS)
@@ -1,11 +1,14 @@
 package gittool
 
 import (
+	"central-cyclone/internal/metrics"
 	"fmt"
 	"log/slog"
 	"os"
+	"time"
 
 	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
 	"github.com/go-git/go-git/v6/plumbing/transport/http"
 )
 
@@ -60,49 +63,74 @@ func (c *ClonedRepo) Pull() error {
 	return nil
 }
 
-func (c *ClonedRepo) CheckoutRevision(revision string) error {
+func (c *ClonedRepo) GetCurrentRevision() (string, error) {
 	repo, err := c.openRepository()
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	w, err := repo.Worktree()
+	ref, err := repo.Head()
 	if err != nil {
-		return fmt.Errorf("failed to get worktree: %w", err)
+		return "", fmt.Errorf("failed to get HEAD: %w", err)
 	}
 
-	slog.Info("🔄 Preparing checkout", "repo", c.RepoUrl, "revision", revision)
+	return ref.Hash().String(), nil
+}
 
-	targetHash, err := resolveTargetHash(repo, revision)
+// GetCurrentTag returns the tag pointing at HEAD, or an empty string if there is none.
+// If multiple tags point at HEAD, the alphabetically last one is returned.
+func (c *ClonedRepo) GetCurrentTag() (string, error) {
+	repo, err := c.openRepository()
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	slog.Info("🏷️  Checking out", "repo", c.RepoUrl, "hash", targetHash.String())
+	head, err := repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("failed to get HEAD: %w", err)
+	}
 
-	err = w.Checkout(&git.CheckoutOptions{
-		Hash:  targetHash,
-		Force: true,
+	tags, err := repo.Tags()
+	if err != nil {
+		return "", fmt.Errorf("failed to list tags: %w", err)
+	}
+
+	var current string
+	err = tags.ForEach(func(ref *plumbing.Reference) error {
+		hash := ref.Hash()
+		// Annotated tags point at a tag object instead of the commit
+		if tagObj, err := repo.TagObject(hash); err == nil {
+			commit, err := tagObj.Commit()
+			if err != nil {
+				return nil
+			}
+			hash = commit.Hash
+		}
+		if hash == head.Hash() && ref.Name().Short() > current {
+			current = ref.Name().Short()
+		}
+		return nil
 	})
 	if err != nil {
-		return fmt.Errorf("failed to checkout revision: %w", err)
+		return "", fmt.Errorf("failed to list tags: %w", err)
 	}
-
-	return nil
+	return current, nil
 }
 
-func (c *ClonedRepo) GetCurrentRevision() (string, error) {
+// ResolveRevision returns the commit hash the given revision resolves to, without checking it out.
+// The optional rules map the revision to a git revision, see RevisionRule.
+func (c *ClonedRepo) ResolveRevision(revision string, rules []RevisionRule) (string, error) {
 	repo, err := c.openRepository()
 	if err != nil {
 		return "", err
 	}
 
-	ref, err := repo.Head()
+	targetHash, err := resolveTargetHash(repo, revision, rules)
 	if err != nil {
-		return "", fmt.Errorf("failed to get HEAD: %w", err)
+		return "", err
 	}
 
-	return ref.Hash().String(), nil
+	return targetHash.String(), nil
 }
 
 func (c *ClonedRepo) UpdateIfAvailable() (bool, error) {
@@ -127,7 +155,13 @@ func (c *ClonedRepo) UpdateIfAvailable() (bool, error) {
 			Password: token,
 		}
 	}
+	start := time.Now()
 	err = w.Pull(pullOpts)
+	pullErr := err
+	if err == git.NoErrAlreadyUpToDate {
+		pullErr = nil
+	}
+	metrics.GitDuration.WithLabelValues("pull", metrics.Result(pullErr)).Observe(time.Since(start).Seconds())
 
 	if err != nil && err == git.NoErrAlreadyUpToDate {
 		return false, nil
@@ -81,152 +81,74 @@ func newTestRepo(t *testing.T, lightweightTag, annotatedTag string) testRepoFixt
 	}
 }
 
-func TestClonedRepo_CheckoutRevision_FullSHA(t *testing.T) {
+func TestClonedRepo_ResolveRevision(t *testing.T) {
 	fixture := newTestRepo(t, "v1.0.0", "v2.0.0")
 	repo := &ClonedRepo{Path: fixture.path}
 
-	if err := repo.CheckoutRevision(fixture.firstCommit); err != nil {
-		t.Fatalf("expected success, got error: %v", err)
-	}
-
-	current, err := repo.GetCurrentRevision()
-	if err != nil {
-		t.Fatalf("failed to get current revision: %v", err)
-	}
-	if current != fixture.firstCommit {
-		t.Fatalf("expected current revision %q, got %q", fixture.firstCommit, current)
-	}
-}
-
-func TestClonedRepo_CheckoutRevision_PlainTag(t *testing.T) {
-	fixture := newTestRepo(t, "v1.0.0", "v2.0.0")
-	repo := &ClonedRepo{Path: fixture.path}
-
-	if err := repo.CheckoutRevision(fixture.lightweightTag); err != nil {
-		t.Fatalf("expected success, got error: %v", err)
-	}
-
-	current, err := repo.GetCurrentRevision()
-	if err != nil {
-		t.Fatalf("failed to get current revision: %v", err)
-	}
-	if current != fixture.firstCommit {
-		t.Fatalf("expected current revision %q, got %q", fixture.firstCommit, current)
-	}
-}
-
-func TestClonedRepo_CheckoutRevision_AnnotatedTag(t *testing.T) {
-	fixture := newTestRepo(t, "v1.0.0", "v2.0.0")
-	repo := &ClonedRepo{Path: fixture.path}
-
-	if err := repo.CheckoutRevision(fixture.annotatedTag); err != nil {
-		t.Fatalf("expected success, got error: %v", err)
-	}
-
-	current, err := repo.GetCurrentRevision()
-	if err != nil {
-		t.Fatalf("failed to get current revision: %v", err)
-	}
-	if current != fixture.secondCommit {
-		t.Fatalf("expected current revision %q, got %q", fixture.secondCommit, current)
-	}
-}
-
-func TestClonedRepo_CheckoutRevision_TagPlusShortHex(t *testing.T) {
-	fixture := newTestRepo(t, "v1.0.0", "v2.0.0")
-	repo := &ClonedRepo{Path: fixture.path}
-
-	shortHex := fixture.firstCommit[:8]
-	revision := fixture.annotatedTag + "+" + shortHex
-
-	if err := repo.CheckoutRevision(revision); err != nil {
-		t.Fatalf("expected success, got error: %v", err)
-	}
-
-	current, err := repo.GetCurrentRevision()
-	if err != nil {
-		t.Fatalf("failed to get current revision: %v", err)
-	}
-	if current != fixture.firstCommit {
-		t.Fatalf("expected current revision %q, got %q", fixture.firstCommit, current)
-	}
-}
-
-func TestClonedRepo_CheckoutRevision_TagPlusFullHex(t *testing.T) {
-	fixture := newTestRepo(t, "v1.0.0", "v2.0.0")
-	repo := &ClonedRepo{Path: fixture.path}
-
-	revision := fixture.lightweightTag + "+" + fixture.secondCommit
-
-	if err := repo.CheckoutRevision(revision); err != nil {
-		t.Fatalf("expected success, got error: %v", err)
+	tests := []struct {
+		name     string
+		revision string
+		want     string
+		wantErr  bool
+	}{
+		{name: "full sha", revision: fixture.firstCommit, want: fixture.firstCommit},
+		{name: "plain tag", revision: fixture.lightweightTag, want: fixture.firstCommit},
+		{name: "annotated tag", revision: fixture.annotatedTag, want: fixture.secondCommit},
+		{name: "tag plus short hex", revision: fixture.annotatedTag + "+" + fixture.firstCommit[:8], want: fixture.firstCommit},
+		{name: "tag plus full hex", revision: fixture.lightweightTag + "+" + fixture.secondCommit, want: fixture.secondCommit},
+		{name: "tag plus non hex suffix", revision: fixture.lightweightTag + "+34asdadasd", wantErr: true},
+		{name: "tag plus short suffix", revision: fixture.lightweightTag + "+ab1", wantErr: true},
+		{name: "tag plus empty suffix", revision: fixture.lightweightTag + "+", wantErr: true},
+		{name: "tag plus unknown hex", revision: fixture.lightweightTag + "+deadbeefdeadbeefdeadbeefdeadbeefdeadbeef", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.ResolveRevision(tt.revision, nil)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), tt.revision) {
+					t.Fatalf("expected error containing full revision %q, got %q, %v", tt.revision, got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected success, got error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("expected revision %q, got %q", tt.want, got)
+			}
+		})
 	}
 
+	// Resolving never touches the checkout of the shared clone
 	current, err := repo.GetCurrentRevision()
 	if err != nil {
 		t.Fatalf("failed to get current revision: %v", err)
 	}
 	if current != fixture.secondCommit {
-		t.Fatalf("expected current revision %q, got %q", fixture.secondCommit, current)
+		t.Errorf("expected checkout to stay at %q, got %q", fixture.secondCommit, current)
 	}
 }
 
-func TestClonedRepo_CheckoutRevision_TagPlusNonHexSuffix(t *testing.T) {
+func TestClonedRepo_GetCurrentTag(t *testing.T) {
 	fixture := newTestRepo(t, "v1.0.0", "v2.0.0")
 	repo := &ClonedRepo{Path: fixture.path}
 
-	revision := fixture.lightweightTag + "+34asdadasd"
-
-	err := repo.CheckoutRevision(revision)
-	if err == nil {
-		t.Fatal("expected error, got nil")
-	}
-	if !strings.Contains(err.Error(), revision) {
-		t.Fatalf("expected error to contain full revision %q, got: %v", revision, err)
-	}
-}
-
-func TestClonedRepo_CheckoutRevision_TagPlusShortSuffix(t *testing.T) {
-	fixture := newTestRepo(t, "v1.0.0", "v2.0.0")
-	repo := &ClonedRepo{Path: fixture.path}
-
-	revision := fixture.lightweightTag + "+ab1"
-
-	err := repo.CheckoutRevision(revision)
-	if err == nil {
-		t.Fatal("expected error, got nil")
-	}
-	if !strings.Contains(err.Error(), revision) {
-		t.Fatalf("expected error to contain full revision %q, got: %v", revision, err)
-	}
-}
-
-func TestClonedRepo_CheckoutRevision_TagPlusEmptySuffix(t *testing.T) {
-	fixture := newTestRepo(t, "v1.0.0", "v2.0.0")
-	repo := &ClonedRepo{Path: fixture.path}
-
-	revision := fixture.lightweightTag + "+"
-
-	err := repo.CheckoutRevision(revision)
-	if err == nil {
-		t.Fatal("expected error, got nil")
-	}
-	if !strings.Contains(err.Error(), revision) {
-		t.Fatalf("expected error to contain full revision %q, got: %v", revision, err)
-	}
-}
-
-func TestClonedRepo_CheckoutRevision_TagPlusUnknownHex(t *testing.T) {
-	fixture := newTestRepo(t, "v1.0.0", "v2.0.0")
-	repo := &ClonedRepo{Path: fixture.path}
-
-	revision := fixture.lightweightTag + "+deadbeefdeadbeefdeadbeefdeadbeefdeadbeef"
-
-	err := repo.CheckoutRevision(revision)
-	if err == nil {
-		t.Fatal("expected error, got nil")
-	}
-	if !strings.Contains(err.Error(), revision) {
-		t.Fatalf("expected error to contain full revision %q, got: %v", revision, err)
+	for _, tt := range []struct{ revision, want string }{
+		{revision: fixture.secondCommit, want: fixture.annotatedTag},
+		{revision: fixture.firstCommit, want: fixture.lightweightTag},
+	} {
+		worktree, err := repo.CreateRevisionWorktree(tt.revision, filepath.Join(t.TempDir(), tt.want), nil)
+		if err != nil {
+			t.Fatalf("failed to check out %s: %v", tt.revision, err)
+		}
+		defer worktree.Remove()
+		tag, err := worktree.GetCurrentTag()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if tag != tt.want {
+			t.Errorf("expected tag %q at %s, got %q", tt.want, tt.revision, tag)
+		}
 	}
 }
@@ -13,7 +13,13 @@ const (
 	maxCommitishHexLen = 40
 )
 
-func resolveTargetHash(repo *git.Repository, revision string) (plumbing.Hash, error) {
+// resolveTargetHash resolves a deployed version to a commit. The rules are tried in order,
+// if none of them resolves, the commitish of build metadata or the version itself is resolved.
+func resolveTargetHash(repo *git.Repository, revision string, rules []RevisionRule) (plumbing.Hash, error) {
+	if hash, ok := resolveWithRules(repo, revision, rules); ok {
+		return hash, nil
+	}
+
 	if commitish, ok := splitBuildMetadataRevision(revision); ok {
 		if hash, err := resolveRevision(repo, commitish); err == nil {
 			return hash, nil
@@ -0,0 +1,89 @@
+package gittool
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+)
+
+var matchAll = regexp.MustCompile(`^.*$`)
+
+// RevisionRule maps a deployed version to a git revision of the application repo.
+// A rule either expands its Revision template with the captures of Match, e.g. v$0 or release/${1},
+// or resolves the head of its Branch, which may use the captures as well.
+type RevisionRule struct {
+	Match    *regexp.Regexp // Matches every version if nil
+	Revision string
+	Branch   string
+}
+
+// NewRevisionRule compiles a rule. Exactly one of revision and branch must be set.
+func NewRevisionRule(match, revision, branch string) (RevisionRule, error) {
+	if (revision == "") == (branch == "") {
+		return RevisionRule{}, fmt.Errorf("revision rule requires either a revision or a branch")
+	}
+
+	rule := RevisionRule{Revision: revision, Branch: branch}
+	if match != "" {
+		pattern, err := regexp.Compile(match)
+		if err != nil {
+			return RevisionRule{}, fmt.Errorf("invalid revision rule match '%s': %w", match, err)
+		}
+		rule.Match = pattern
+	}
+	return rule, nil
+}
+
+func (r RevisionRule) String() string {
+	match := "*"
+	if r.Match != nil {
+		match = r.Match.String()
+	}
+	if r.Branch != "" {
+		return fmt.Sprintf("%s -> branch %s", match, r.Branch)
+	}
+	return fmt.Sprintf("%s -> %s", match, r.Revision)
+}
+
+// targets returns the revisions to resolve for the version, or false if the rule does not match.
+// Branches are looked up as remote tracking branch first, as clones only contain the default branch locally.
+func (r RevisionRule) targets(version string) ([]string, bool) {
+	match := r.Match
+	if match == nil {
+		match = matchAll
+	}
+
+	submatches := match.FindStringSubmatchIndex(version)
+	if submatches == nil {
+		return nil, false
+	}
+	if r.Branch != "" {
+		branch := string(match.ExpandString(nil, r.Branch, version, submatches))
+		return []string{"refs/remotes/origin/" + branch, branch}, true
+	}
+	return []string{string(match.ExpandString(nil, r.Revision, version, submatches))}, true
+}
+
+// resolveWithRules tries the rules in order and returns the hash of the first matching rule that resolves
+func resolveWithRules(repo *git.Repository, version string, rules []RevisionRule) (plumbing.Hash, bool) {
+	for i, rule := range rules {
+		targets, ok := rule.targets(version)
+		if !ok {
+			continue
+		}
+
+		for _, target := range targets {
+			hash, err := resolveRevision(repo, target)
+			if err != nil {
+				slog.Debug("Revision rule matched, but its revision could not be resolved", "version", version, "rule", i, "target", target, "error", err)
+				continue
+			}
+			slog.Info("Resolved version with revision rule", "version", version, "rule", i, "definition", rule.String(), "revision", target, "hash", hash.String())
+			return hash, true
+		}
+	}
+	return plumbing.ZeroHash, false
+}
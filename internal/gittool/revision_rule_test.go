@@ -0,0 +1,94 @@
+package gittool
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v6/plumbing"
+)
+
+func mustRevisionRule(t *testing.T, match, revision, branch string) RevisionRule {
+	t.Helper()
+	rule, err := NewRevisionRule(match, revision, branch)
+	if err != nil {
+		t.Fatalf("failed to create revision rule: %v", err)
+	}
+	return rule
+}
+
+func TestNewRevisionRule_Validation(t *testing.T) {
+	if _, err := NewRevisionRule("", "", ""); err == nil {
+		t.Error("expected error for rule without revision and branch")
+	}
+	if _, err := NewRevisionRule("", "v$0", "main"); err == nil {
+		t.Error("expected error for rule with revision and branch")
+	}
+	if _, err := NewRevisionRule("(", "v$0", ""); err == nil {
+		t.Error("expected error for invalid match")
+	}
+}
+
+func TestResolveTargetHash_WithRevisionRules(t *testing.T) {
+	fixture := newTestRepo(t, "v1.0.0", "ci/8812")
+	repo, err := (&ClonedRepo{Path: fixture.path}).openRepository()
+	if err != nil {
+		t.Fatalf("failed to open repository: %v", err)
+	}
+	branchRef := plumbing.NewHashReference(plumbing.NewBranchReferenceName("release-1.4"), plumbing.NewHash(fixture.firstCommit))
+	if err := repo.Storer.SetReference(branchRef); err != nil {
+		t.Fatalf("failed to create branch: %v", err)
+	}
+
+	rules := []RevisionRule{
+		mustRevisionRule(t, `^build-(\d+)$`, "ci/${1}", ""),
+		mustRevisionRule(t, `^\d+\.\d+\.\d+$`, "v$0", ""),
+		mustRevisionRule(t, `^(\d+\.\d+)\.\d+-rc\d+$`, "", "release-${1}"),
+		mustRevisionRule(t, `^release/(\d+)\.(\d+)$`, "", "release-1.4"),
+	}
+
+	tests := []struct {
+		version  string
+		wantHash string
+	}{
+		{version: "build-8812", wantHash: fixture.secondCommit},
+		{version: "1.0.0", wantHash: fixture.firstCommit},
+		{version: "release/1.4", wantHash: fixture.firstCommit},
+		{version: "1.4.0-rc2", wantHash: fixture.firstCommit},
+		// No rule matches, falls back to resolving the version itself
+		{version: fixture.secondCommit, wantHash: fixture.secondCommit},
+		// Matching rule does not resolve, falls back to the build metadata commitish
+		{version: "1.0.0+" + fixture.secondCommit[:8], wantHash: fixture.secondCommit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			hash, err := resolveTargetHash(repo, tt.version, rules)
+			if err != nil {
+				t.Fatalf("expected success, got error: %v", err)
+			}
+			if hash.String() != tt.wantHash {
+				t.Errorf("expected hash %q, got %q", tt.wantHash, hash.String())
+			}
+		})
+	}
+}
+
+func TestResolveTargetHash_RemoteTrackingBranch(t *testing.T) {
+	fixture := newTestRepo(t, "v1.0.0", "v2.0.0")
+	repo, err := (&ClonedRepo{Path: fixture.path}).openRepository()
+	if err != nil {
+		t.Fatalf("failed to open repository: %v", err)
+	}
+	remoteRef := plumbing.NewHashReference(plumbing.NewRemoteReferenceName("origin", "develop"), plumbing.NewHash(fixture.secondCommit))
+	if err := repo.Storer.SetReference(remoteRef); err != nil {
+		t.Fatalf("failed to create remote branch: %v", err)
+	}
+
+	rules := []RevisionRule{mustRevisionRule(t, "", "", "develop")}
+	hash, err := resolveTargetHash(repo, "2024.10.3-rc1", rules)
+	if err != nil {
+		t.Fatalf("expected success, got error: %v", err)
+	}
+	if hash.String() != fixture.secondCommit {
+		t.Errorf("expected hash %q, got %q", fixture.secondCommit, hash.String())
+	}
+}
@@ -221,7 +221,7 @@ func TestResolveTargetHash_PrefersCommitishOverFullString(t *testing.T) {
 	}
 
 	revision := fixture.annotatedTag + "+" + fixture.firstCommit[:8]
-	hash, err := resolveTargetHash(repo, revision)
+	hash, err := resolveTargetHash(repo, revision, nil)
 	if err != nil {
 		t.Fatalf("expected success, got error: %v", err)
 	}
@@ -239,7 +239,7 @@ func TestResolveTargetHash_FallsBackToFullStringWhenCommitishUnresolvable(t *tes
 	}
 
 	revision := fixture.lightweightTag + "+deadbeefdeadbeefdeadbeefdeadbeefdeadbeef"
-	_, err = resolveTargetHash(repo, revision)
+	_, err = resolveTargetHash(repo, revision, nil)
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -257,7 +257,7 @@ func TestResolveTargetHash_FallsBackWhenSuffixIsNotHex(t *testing.T) {
 	}
 
 	revision := fixture.lightweightTag + "+34asdadasd"
-	_, err = resolveTargetHash(repo, revision)
+	_, err = resolveTargetHash(repo, revision, nil)
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -275,7 +275,7 @@ func TestResolveTargetHash_PrefersCommitishForSemverHyphenFormat(t *testing.T) {
 	}
 
 	revision := "1.4.5-" + fixture.firstCommit[:8]
-	hash, err := resolveTargetHash(repo, revision)
+	hash, err := resolveTargetHash(repo, revision, nil)
 	if err != nil {
 		t.Fatalf("expected success, got error: %v", err)
 	}
@@ -0,0 +1,92 @@
+package gittool
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/go-git/go-billy/v6/osfs"
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/x/plumbing/worktree"
+)
+
+var invalidWorktreeNameChars = regexp.MustCompile(`[^a-zA-Z0-9\-]`)
+
+// RevisionWorktree is a linked worktree of a ClonedRepo with a single revision checked out.
+// It shares the objects of the ClonedRepo but has its own directory, index and HEAD.
+// Hence, multiple revisions of the same repo can be analyzed at the same time.
+type RevisionWorktree struct {
+	ClonedRepo
+	Revision string
+	Hash     string
+	name     string
+	parent   *git.Repository
+}
+
+// CreateRevisionWorktree checks out the given revision into a new linked worktree at path.
+// The optional rules map the revision to a git revision, see RevisionRule.
+// The folder at path must be empty. Call Remove once the worktree is no longer needed.
+func (c *ClonedRepo) CreateRevisionWorktree(revision string, path string, rules []RevisionRule) (RevisionWorktree, error) {
+	repo, err := c.openRepository()
+	if err != nil {
+		return RevisionWorktree{}, err
+	}
+
+	slog.Info("🔄 Preparing worktree", "repo", c.RepoUrl, "revision", revision)
+
+	targetHash, err := resolveTargetHash(repo, revision, rules)
+	if err != nil {
+		return RevisionWorktree{}, err
+	}
+
+	worktrees, err := worktree.New(repo.Storer)
+	if err != nil {
+		return RevisionWorktree{}, fmt.Errorf("failed to access worktrees: %w", err)
+	}
+
+	name := invalidWorktreeNameChars.ReplaceAllString(filepath.Base(path), "-")
+	worktreeFs := osfs.New(path)
+
+	slog.Info("🏷️  Checking out worktree", "repo", c.RepoUrl, "hash", targetHash.String(), "path", path)
+
+	if err := worktrees.Add(worktreeFs, name, worktree.WithCommit(targetHash), worktree.WithDetachedHead()); err != nil {
+		_ = worktrees.Remove(name)
+		return RevisionWorktree{}, fmt.Errorf("failed to create worktree for revision %q: %w", revision, err)
+	}
+
+	worktreeRepo, err := worktrees.Open(worktreeFs)
+	if err != nil {
+		_ = worktrees.Remove(name)
+		return RevisionWorktree{}, fmt.Errorf("failed to open worktree: %w", err)
+	}
+
+	return RevisionWorktree{
+		ClonedRepo: ClonedRepo{
+			Path:    path,
+			RepoUrl: c.RepoUrl,
+			repo:    worktreeRepo,
+		},
+		Revision: revision,
+		Hash:     targetHash.String(),
+		name:     name,
+		parent:   repo,
+	}, nil
+}
+
+// Remove deletes the worktree folder and unregisters the worktree from its repository
+func (w *RevisionWorktree) Remove() error {
+	if err := os.RemoveAll(w.Path); err != nil {
+		return fmt.Errorf("failed to remove worktree folder %s: %w", w.Path, err)
+	}
+
+	worktrees, err := worktree.New(w.parent.Storer)
+	if err != nil {
+		return fmt.Errorf("failed to access worktrees: %w", err)
+	}
+	if err := worktrees.Remove(w.name); err != nil {
+		return fmt.Errorf("failed to unregister worktree %s: %w", w.name, err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package gittool
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestClonedRepo_CreateRevisionWorktree_ChecksOutRevisionInSeparateFolder(t *testing.T) {
+	fixture := newTestRepo(t, "v1.0.0", "v2.0.0")
+	repo := &ClonedRepo{Path: fixture.path, RepoUrl: fixture.path}
+
+	firstPath := filepath.Join(t.TempDir(), "first")
+	secondPath := filepath.Join(t.TempDir(), "second")
+
+	first, err := repo.CreateRevisionWorktree(fixture.lightweightTag, firstPath, nil)
+	if err != nil {
+		t.Fatalf("failed to create first worktree: %v", err)
+	}
+	second, err := repo.CreateRevisionWorktree(fixture.annotatedTag, secondPath, nil)
+	if err != nil {
+		t.Fatalf("failed to create second worktree: %v", err)
+	}
+
+	if first.Hash != fixture.firstCommit {
+		t.Errorf("expected first worktree hash %q, got %q", fixture.firstCommit, first.Hash)
+	}
+	current, err := second.GetCurrentRevision()
+	if err != nil {
+		t.Fatalf("failed to get current revision: %v", err)
+	}
+	if current != fixture.secondCommit {
+		t.Errorf("expected second worktree at %q, got %q", fixture.secondCommit, current)
+	}
+
+	if _, err := os.Stat(filepath.Join(firstPath, "CHANGES.md")); !os.IsNotExist(err) {
+		t.Error("expected CHANGES.md to be missing in the worktree of the first commit")
+	}
+	if _, err := os.Stat(filepath.Join(secondPath, "CHANGES.md")); err != nil {
+		t.Errorf("expected CHANGES.md in the worktree of the second commit: %v", err)
+	}
+
+	// The shared clone itself must stay untouched
+	mainRevision, err := repo.GetCurrentRevision()
+	if err != nil {
+		t.Fatalf("failed to get main revision: %v", err)
+	}
+	if mainRevision != fixture.secondCommit {
+		t.Errorf("expected main checkout to stay at %q, got %q", fixture.secondCommit, mainRevision)
+	}
+
+	if err := first.Remove(); err != nil {
+		t.Fatalf("failed to remove worktree: %v", err)
+	}
+	if _, err := os.Stat(firstPath); !os.IsNotExist(err) {
+		t.Error("expected worktree folder to be removed")
+	}
+	if _, err := os.Stat(filepath.Join(fixture.path, ".git", "worktrees", "first")); !os.IsNotExist(err) {
+		t.Error("expected worktree to be unregistered from the repository")
+	}
+}
+
+func TestClonedRepo_CreateRevisionWorktree_UnknownRevision(t *testing.T) {
+	fixture := newTestRepo(t, "v1.0.0", "v2.0.0")
+	repo := &ClonedRepo{Path: fixture.path}
+
+	if _, err := repo.CreateRevisionWorktree("does-not-exist", filepath.Join(t.TempDir(), "wt"), nil); err == nil {
+		t.Fatal("expected error for unknown revision")
+	}
+}
@@ -2,85 +2,207 @@ package handlers
 
 import (
 	"central-cyclone/internal/analyzer"
+	"central-cyclone/internal/buildinfo"
 	"central-cyclone/internal/config"
 	"central-cyclone/internal/gittool"
 	"central-cyclone/internal/models"
+	"central-cyclone/internal/provenance"
 	"central-cyclone/internal/upload"
 	"central-cyclone/internal/workspace"
 	"context"
 	"fmt"
 	"log/slog"
+	"strings"
+	"sync"
+	"time"
 )
 
-func AnalyzeAndSave(settings *config.Settings, gitTool gittool.Cloner, workspaceHandler workspace.Workspace) {
-	if settings != nil && len(settings.Repositories) != 0 {
-		analyzeRepos(settings.Repositories, gitTool, workspaceHandler, nil)
-	}
+// AnalyzeAndSave analyzes all configured repositories and saves the SBOMs to the workspace.
+// At most parallelism repositories are analyzed at the same time. Cancelling the context kills running generators.
+func AnalyzeAndSave(ctx context.Context, settings *config.Settings, gitTool gittool.Cloner, workspaceHandler workspace.Workspace, parallelism int) RunReport {
+	return analyzeRepos(ctx, repositories(settings), gitTool, analyzer.NewDefaultGeneratorRegistry(), workspaceHandler, nil, parallelism)
+}
+
+// AnalyzeAndUpload analyzes all configured repositories and uploads the SBOMs.
+// At most parallelism repositories are analyzed at the same time. Cancelling the context kills running generators.
+func AnalyzeAndUpload(ctx context.Context, settings *config.Settings, gitTool gittool.Cloner, workspaceHandler workspace.Workspace, uploader upload.Uploader, parallelism int) RunReport {
+	return analyzeRepos(ctx, repositories(settings), gitTool, analyzer.NewDefaultGeneratorRegistry(), workspaceHandler, uploader, parallelism)
 }
 
-func AnalyzeAndUpload(settings *config.Settings, gitTool gittool.Cloner, workspaceHandler workspace.Workspace, uploader upload.Uploader) {
-	if settings != nil && len(settings.Repositories) != 0 {
-		analyzeRepos(settings.Repositories, gitTool, workspaceHandler, uploader)
+func repositories(settings *config.Settings) []config.Repo {
+	if settings == nil {
+		return nil
 	}
+	return settings.Repositories
 }
 
-func analyzeRepos(repoSettings []config.Repo, gitTool gittool.Cloner, workspaceHandler workspace.Workspace, uploader upload.Uploader) {
-	slog.Info("Found repositories to analyze", "count", len(repoSettings))
+func analyzeRepos(ctx context.Context, repoSettings []config.Repo, gitTool gittool.Cloner, sbomAnalyzer analyzer.Analyzer, workspaceHandler workspace.Workspace, uploader upload.Uploader, parallelism int) RunReport {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	slog.Info("Found repositories to analyze", "count", len(repoSettings), "parallelism", parallelism)
 
-	for _, repo := range repoSettings {
-		err := analyzeRepo(&repo, gitTool, workspaceHandler, uploader)
-		if err != nil {
-			slog.Error("Could not analyze repo", "repo", repo.Url, "error", err)
-		}
+	report := RunReport{StartedAt: time.Now(), Repos: make([]RepoResult, len(repoSettings))}
+	semaphore := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+
+	for i, repo := range repoSettings {
+		wg.Add(1)
+		go func(i int, repo config.Repo) {
+			defer wg.Done()
+
+			// Acquire semaphore
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			if ctx.Err() != nil {
+				report.Repos[i] = RepoResult{RepoUrl: repo.Url, Status: StatusFailed, Error: "analysis cancelled", Targets: []TargetResult{}}
+				return
+			}
+
+			// Every goroutine writes its own index, keeping the configured order
+			report.Repos[i] = analyzeRepo(ctx, &repo, gitTool, sbomAnalyzer, workspaceHandler, uploader)
+			if report.Repos[i].Status == StatusFailed {
+				slog.Error("Could not analyze repo", "repo", repo.Url, "error", report.Repos[i].Error)
+			}
+		}(i, repo)
 	}
 
+	wg.Wait()
+	report.DurationSeconds = time.Since(report.StartedAt).Seconds()
+	return report
 }
 
-func uploadSbom(uploader upload.Uploader, sbom models.Sbom) error {
-	err := uploader.UploadSBOM(context.TODO(), sbom)
+func uploadSbom(ctx context.Context, logger *slog.Logger, uploader upload.Uploader, sbom models.Sbom) (*upload.UploadResult, error) {
+	uploadResult, err := uploader.UploadSBOM(ctx, sbom)
 	if err != nil {
-		slog.Error("Could not upload SBOM", "error", err)
-		return err
+		logger.Error("Could not upload SBOM", "error", err)
+		return nil, err
 	}
-	return nil
+	return uploadResult, nil
 }
 
-func analyzeRepo(repo *config.Repo, gitTool gittool.Cloner, workspaceHandler workspace.Workspace, uploader upload.Uploader) error {
-	slog.Info("🔎 Analyzing repository", "repo", repo.Url)
+// analyzeRepo clones and analyzes a single repository. All log entries carry the repo URL,
+// as multiple repositories may be analyzed at the same time.
+func analyzeRepo(ctx context.Context, repo *config.Repo, gitTool gittool.Cloner, sbomAnalyzer analyzer.Analyzer, workspaceHandler workspace.Workspace, uploader upload.Uploader) (result RepoResult) {
+	start := time.Now()
+	result = RepoResult{RepoUrl: repo.Url, Targets: []TargetResult{}}
+	defer func() {
+		result.DurationSeconds = time.Since(start).Seconds()
+	}()
 
-	cdxAnalyzer := analyzer.CdxgenAnalyzer{}
+	logger := slog.With("repo", repo.Url)
+	logger.Info("🔎 Analyzing repository")
 
 	clonedRepo, err := gitTool.CloneRepo(repo.Url)
 	if err != nil {
-		slog.Error("Could not clone repository", "repo", repo.Url, "error", err)
-		return fmt.Errorf("error cloning repository: %w", err)
+		logger.Error("Could not clone repository", "error", err)
+		result.Status = StatusFailed
+		result.Error = fmt.Sprintf("error cloning repository: %v", err)
+		return result
 	}
+	result.Status = StatusCloned
+	source := repoProvenance(logger, repo.Url, clonedRepo)
 
+	// Targets are independent, a failing target does not stop the analysis of the remaining ones
+	var targetErrors []string
 	for _, t := range repo.Targets {
-		slog.Info("🔬 Analyzing repo", "repo", repo.Url, "target", t.Type)
+		targetResult := analyzeTarget(ctx, logger, clonedRepo, source, t, sbomAnalyzer, workspaceHandler, uploader)
+		result.Targets = append(result.Targets, targetResult)
 
-		scanTarget := &analyzer.ScanTarget{
-			ProjectId:   t.ProjectId,
-			ProjectType: t.Type,
-			Directory:   t.Directory,
+		if targetResult.Status == StatusFailed {
+			logger.Error("Could not analyze target", "target", t.Type.String(), "project", targetResult.Project(), "error", targetResult.Error)
+			targetErrors = append(targetErrors, fmt.Sprintf("target %s (%s): %s", targetResult.Project(), t.Type.String(), targetResult.Error))
 		}
+	}
+
+	if len(targetErrors) > 0 {
+		result.Status = StatusFailed
+		result.Error = fmt.Sprintf("%d of %d targets failed: %s", len(targetErrors), len(repo.Targets), strings.Join(targetErrors, "; "))
+		return result
+	}
+
+	logger.Info("✅ Finished analyzing repo")
+	return result
+}
+
+// analyzeTarget analyzes a single target of a cloned repository and uploads or saves its SBOM
+func analyzeTarget(ctx context.Context, logger *slog.Logger, clonedRepo gittool.ClonedRepo, source provenance.Provenance, t config.RepoTarget, sbomAnalyzer analyzer.Analyzer, workspaceHandler workspace.Workspace, uploader upload.Uploader) (result TargetResult) {
+	start := time.Now()
+	result = newTargetResult(t, StatusFailed)
+	defer func() {
+		result.DurationSeconds = time.Since(start).Seconds()
+	}()
+
+	scanTarget := t.ScanTarget()
+	logger.Info("🔬 Analyzing repo", "target", t.Type.String(), "generator", scanTarget.GetGenerator())
+
+	sbom, err := sbomAnalyzer.AnalyzeProject(ctx, clonedRepo, scanTarget)
+	if err != nil {
+		result.Error = fmt.Sprintf("error analyzing project: %v", err)
+		return result
+	}
+
+	source.Timestamp = time.Now()
+	sbom.Data, err = provenance.Apply(sbom.Data, source)
+	if err != nil {
+		result.Error = fmt.Sprintf("error adding provenance: %v", err)
+		return result
+	}
+	sbom.RepoUrl = source.RepoUrl
+	sbom.Commit = source.Commit
+	result.Status = StatusAnalyzed
 
-		sbom, err := cdxAnalyzer.AnalyzeProject(clonedRepo, scanTarget)
+	if uploader != nil {
+		uploadResult, err := uploadSbom(ctx, logger, uploader, sbom)
 		if err != nil {
-			return fmt.Errorf("error analyzing project: %v", err)
+			result.Status = StatusFailed
+			result.Error = fmt.Sprintf("error uploading sbom: %v", err)
+			return result
 		}
+		result.Status = StatusUploaded
+		result.Upload = uploadResult
+		return result
+	}
 
-		if uploader != nil {
-			_ = uploadSbom(uploader, sbom)
-		} else {
-			err := workspaceHandler.SaveSbom(sbom)
-			if err != nil {
-				slog.Error("Could not save sbom", "error", err)
-				return fmt.Errorf("error saving sbom: %v", err)
-			}
-		}
+	if err := workspaceHandler.SaveSbom(sbom); err != nil {
+		logger.Error("Could not save sbom", "error", err)
+		result.Status = StatusFailed
+		result.Error = fmt.Sprintf("error saving sbom: %v", err)
+		return result
+	}
+	result.Status = StatusSaved
+	return result
+}
 
+// repoProvenance describes the checked out revision of the repo. A missing commit or tag is logged,
+// but does not fail the analysis.
+func repoProvenance(logger *slog.Logger, repoUrl string, clonedRepo gittool.ClonedRepo) provenance.Provenance {
+	source := provenance.Provenance{RepoUrl: repoUrl, ToolVersion: buildinfo.Version}
+
+	commit, err := clonedRepo.GetCurrentRevision()
+	if err != nil {
+		logger.Warn("Could not get commit of repo", "error", err)
+	}
+	source.Commit = commit
+
+	tag, err := clonedRepo.GetCurrentTag()
+	if err != nil {
+		logger.Warn("Could not get tag of repo", "error", err)
+	}
+	source.Version = tag
+
+	return source
+}
+
+func newTargetResult(t config.RepoTarget, status Status) TargetResult {
+	result := TargetResult{ProjectId: t.ProjectId, ProjectType: t.Type.String(), Status: status}
+	if t.ProjectId == "" {
+		result.ProjectName = t.ProjectName
+		result.ProjectVersion = t.ProjectVersion
+	}
+	if t.Directory != nil {
+		result.Directory = *t.Directory
 	}
-	slog.Info("✅ Finished analyzing repo", "repo", repo.Url)
-	return nil
+	return result
 }
@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"central-cyclone/internal/analyzer"
+	"central-cyclone/internal/config"
+	"central-cyclone/internal/gittool"
+	"central-cyclone/internal/models"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeCloner returns a ClonedRepo at path without cloning, or at a nonexistent path if empty.
+// Repos listed in errs fail to clone.
+type fakeCloner struct {
+	path string
+	errs map[string]error
+}
+
+func (c *fakeCloner) CloneRepo(repoUrl string) (gittool.ClonedRepo, error) {
+	if err := c.errs[repoUrl]; err != nil {
+		return gittool.ClonedRepo{}, err
+	}
+	path := c.path
+	if path == "" {
+		path = "/nonexistent/" + repoUrl
+	}
+	return gittool.ClonedRepo{Path: path, RepoUrl: repoUrl}, nil
+}
+
+func (c *fakeCloner) CloneOrUpdateRepo(repoUrl string) (gittool.ClonedRepo, error) {
+	return c.CloneRepo(repoUrl)
+}
+
+func (c *fakeCloner) CreateRevisionWorktree(repo gittool.ClonedRepo, revision string, rules []gittool.RevisionRule) (gittool.RevisionWorktree, error) {
+	return gittool.RevisionWorktree{}, fmt.Errorf("not supported")
+}
+
+// fakeAnalyzer records the analyzed projects and the peak number of concurrent runs. Projects listed in errs fail.
+// If set, every run is announced on started and blocks until it receives from release.
+type fakeAnalyzer struct {
+	errs     map[string]error
+	started  chan string
+	release  chan struct{}
+	mu       sync.Mutex
+	analyzed []string
+	active   int
+	peak     int
+}
+
+func (a *fakeAnalyzer) AnalyzeProject(ctx context.Context, repo gittool.ClonedRepo, target *analyzer.ScanTarget) (models.Sbom, error) {
+	a.mu.Lock()
+	a.analyzed = append(a.analyzed, target.ProjectId)
+	a.active++
+	a.peak = max(a.peak, a.active)
+	a.mu.Unlock()
+
+	if a.started != nil {
+		a.started <- target.ProjectId
+	}
+	if a.release != nil {
+		<-a.release
+	}
+
+	a.mu.Lock()
+	a.active--
+	a.mu.Unlock()
+
+	if err := a.errs[target.ProjectId]; err != nil {
+		return models.Sbom{}, err
+	}
+	return models.Sbom{ProjectId: target.ProjectId, ProjectType: target.ProjectType(), Data: `{"bomFormat":"CycloneDX"}`}, nil
+}
+
+// fakeWorkspace records the saved SBOMs
+type fakeWorkspace struct {
+	mu    sync.Mutex
+	saved []string
+}
+
+func (w *fakeWorkspace) Clear() error                                           { return nil }
+func (w *fakeWorkspace) CreateRepoFolder(repoUrl string) (string, error)        { return "", nil }
+func (w *fakeWorkspace) CreateWorktreeFolder(repoUrl string) (string, error)    { return "", nil }
+func (w *fakeWorkspace) ReadFileFromRepo(repoPath, path string) ([]byte, error) { return nil, nil }
+func (w *fakeWorkspace) GlobFilesInRepo(repoPath, pattern string) ([]string, error) {
+	return nil, nil
+}
+
+func (w *fakeWorkspace) SaveSbom(sbom models.Sbom) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.saved = append(w.saved, sbom.ProjectId)
+	return nil
+}
+
+func TestAnalyzeRepos_ContinuesAfterFailingTarget(t *testing.T) {
+	repos := []config.Repo{{
+		Url: "https://github.com/org/repo.git",
+		Targets: []config.RepoTarget{
+			{ProjectId: "first", Type: config.ProjectTypes{"go"}},
+			{ProjectId: "second", Type: config.ProjectTypes{"java"}},
+			{ProjectId: "third", Type: config.ProjectTypes{"js"}},
+		},
+	}}
+	sbomAnalyzer := &fakeAnalyzer{errs: map[string]error{
+		"first": fmt.Errorf("generator crashed"),
+		"third": fmt.Errorf("generator timed out"),
+	}}
+	ws := &fakeWorkspace{}
+
+	report := analyzeRepos(context.Background(), repos, &fakeCloner{}, sbomAnalyzer, ws, nil, 1)
+
+	if !slices.Equal(sbomAnalyzer.analyzed, []string{"first", "second", "third"}) {
+		t.Errorf("expected all targets to be analyzed, got %v", sbomAnalyzer.analyzed)
+	}
+	if !slices.Equal(ws.saved, []string{"second"}) {
+		t.Errorf("expected the second target to be saved, got %v", ws.saved)
+	}
+
+	repo := report.Repos[0]
+	if repo.Status != StatusFailed {
+		t.Errorf("expected repo status %s, got %s", StatusFailed, repo.Status)
+	}
+	for _, want := range []string{"2 of 3 targets failed", "target first (go): error analyzing project: generator crashed", "target third (js): error analyzing project: generator timed out"} {
+		if !strings.Contains(repo.Error, want) {
+			t.Errorf("expected repo error to contain %q, got %q", want, repo.Error)
+		}
+	}
+
+	statuses := []Status{}
+	for _, target := range repo.Targets {
+		statuses = append(statuses, target.Status)
+	}
+	if !slices.Equal(statuses, []Status{StatusFailed, StatusSaved, StatusFailed}) {
+		t.Errorf("unexpected target statuses %v", statuses)
+	}
+}
+
+func TestAnalyzeRepos_BoundedParallelism(t *testing.T) {
+	const parallelism = 3
+	repos := make([]config.Repo, 7)
+	for i := range repos {
+		repos[i] = config.Repo{
+			Url:     fmt.Sprintf("https://github.com/org/repo-%d.git", i),
+			Targets: []config.RepoTarget{{ProjectId: fmt.Sprintf("project-%d", i), Type: config.ProjectTypes{"go"}}},
+		}
+	}
+	sbomAnalyzer := &fakeAnalyzer{started: make(chan string, len(repos)), release: make(chan struct{})}
+
+	done := make(chan RunReport)
+	go func() {
+		done <- analyzeRepos(context.Background(), repos, &fakeCloner{}, sbomAnalyzer, &fakeWorkspace{}, nil, parallelism)
+	}()
+
+	// Wait until the first repos are in flight, then release one run after another, which starts the next repo
+	for range parallelism {
+		<-sbomAnalyzer.started
+	}
+	for i := range repos {
+		sbomAnalyzer.mu.Lock()
+		active := sbomAnalyzer.active
+		sbomAnalyzer.mu.Unlock()
+		if active > parallelism {
+			t.Errorf("expected at most %d concurrent repos, got %d", parallelism, active)
+		}
+		sbomAnalyzer.release <- struct{}{}
+		if i+parallelism < len(repos) {
+			<-sbomAnalyzer.started
+		}
+	}
+	report := <-done
+
+	if sbomAnalyzer.peak != parallelism {
+		t.Errorf("expected %d concurrent repos at peak, got %d", parallelism, sbomAnalyzer.peak)
+	}
+	if len(report.Repos) != len(repos) {
+		t.Fatalf("expected %d repo results, got %d", len(repos), len(report.Repos))
+	}
+	for i, repo := range report.Repos {
+		if repo.RepoUrl != repos[i].Url {
+			t.Errorf("expected repo %d to be %s, got %s", i, repos[i].Url, repo.RepoUrl)
+		}
+		if len(repo.Targets) != 1 || repo.Targets[0].ProjectId != repos[i].Targets[0].ProjectId || repo.Targets[0].Status != StatusSaved {
+			t.Errorf("expected the target of repo %s to be saved, got %+v", repo.RepoUrl, repo.Targets)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"central-cyclone/internal/analyzer"
+	"central-cyclone/internal/config"
+	"central-cyclone/internal/gittool"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+)
+
+// AnalysisPlanEntry describes a target that would be analyzed
+type AnalysisPlanEntry struct {
+	RepoUrl     string
+	Commit      string
+	Project     string // Project id or name@version
+	ProjectType string
+	Directory   string
+	Generator   string
+	Error       string // Set if the repo could not be cloned or the target cannot be resolved
+}
+
+// PlanAnalysis clones the configured repositories and returns the targets that would be analyzed,
+// without running an analysis
+func PlanAnalysis(settings *config.Settings, gitTool gittool.Cloner) []AnalysisPlanEntry {
+	var entries []AnalysisPlanEntry
+	if settings == nil {
+		return entries
+	}
+	generators := analyzer.NewDefaultGeneratorRegistry()
+
+	for _, repo := range settings.Repositories {
+		clonedRepo, commit, err := cloneAndGetRevision(repo.Url, gitTool)
+		if err != nil {
+			slog.Error("Could not plan repo", "repo", repo.Url, "error", err)
+		}
+
+		for _, t := range repo.Targets {
+			target := t.ScanTarget()
+			entry := AnalysisPlanEntry{
+				RepoUrl:     repo.Url,
+				Commit:      commit,
+				Project:     target.Project(),
+				ProjectType: t.Type.String(),
+				Generator:   target.GetGenerator(),
+			}
+			if t.Directory != nil {
+				entry.Directory = *t.Directory
+			}
+			if err != nil {
+				entry.Error = err.Error()
+			} else if targetErr := resolveTarget(generators, clonedRepo, target); targetErr != nil {
+				slog.Error("Could not plan target", "repo", repo.Url, "project", entry.Project, "error", targetErr)
+				entry.Error = targetErr.Error()
+			}
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+func cloneAndGetRevision(repoUrl string, gitTool gittool.Cloner) (gittool.ClonedRepo, string, error) {
+	clonedRepo, err := gitTool.CloneRepo(repoUrl)
+	if err != nil {
+		return gittool.ClonedRepo{}, "", err
+	}
+	commit, err := clonedRepo.GetCurrentRevision()
+	return clonedRepo, commit, err
+}
+
+// resolveTarget checks that the target can be analyzed in the cloned repo, without running its generator
+func resolveTarget(generators *analyzer.GeneratorRegistry, repo gittool.ClonedRepo, target *analyzer.ScanTarget) error {
+	if err := target.ValidateProject(); err != nil {
+		return err
+	}
+	if err := generators.Validate(target); err != nil {
+		return fmt.Errorf("%s: %w", target.GetGenerator(), err)
+	}
+	if target.Directory != nil && *target.Directory != "" {
+		info, err := os.Stat(filepath.Join(repo.Path, *target.Directory))
+		if err != nil || !info.IsDir() {
+			return fmt.Errorf("directory %s does not exist in the repo", *target.Directory)
+		}
+	}
+	return nil
+}
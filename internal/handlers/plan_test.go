@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"central-cyclone/internal/config"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	git "github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing/object"
+)
+
+// newPlanRepo creates a git repo with a backend folder and returns its path and head commit
+func newPlanRepo(t *testing.T) (string, string) {
+	t.Helper()
+	path := t.TempDir()
+	r, err := git.PlainInit(path, false)
+	if err != nil {
+		t.Fatalf("init repo: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(path, "backend"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(path, "backend", "go.mod"), []byte("module backend\n"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	wt, err := r.Worktree()
+	if err != nil {
+		t.Fatalf("worktree: %v", err)
+	}
+	if _, err := wt.Add("backend/go.mod"); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	commit, err := wt.Commit("add backend", &git.CommitOptions{
+		Author: &object.Signature{Name: "Tester", Email: "t@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	return path, commit.String()
+}
+
+// installGeneratorTraps puts generators on the PATH, which write the marker file if they are run
+func installGeneratorTraps(t *testing.T) string {
+	t.Helper()
+	binDir := t.TempDir()
+	marker := filepath.Join(t.TempDir(), "generator-ran")
+	for _, name := range []string{"cdxgen", "syft"} {
+		script := "#!/bin/sh\ntouch \"" + marker + "\"\n"
+		if err := os.WriteFile(filepath.Join(binDir, name), []byte(script), 0o755); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return marker
+}
+
+func TestPlanAnalysis(t *testing.T) {
+	repoPath, commit := newPlanRepo(t)
+	backend := "backend"
+	frontend := "frontend"
+	repoUrl := "https://github.com/org/repo.git"
+	unreachableUrl := "https://github.com/org/unreachable.git"
+
+	tests := []struct {
+		name    string
+		repoUrl string
+		target  config.RepoTarget
+		want    AnalysisPlanEntry
+	}{
+		{
+			name:    "project id with default generator",
+			repoUrl: repoUrl,
+			target:  config.RepoTarget{ProjectId: "uuid-1", Type: config.ProjectTypes{"java", "js"}},
+			want:    AnalysisPlanEntry{RepoUrl: repoUrl, Commit: commit, Project: "uuid-1", ProjectType: "java,js", Generator: "cdxgen"},
+		},
+		{
+			name:    "name and version with generator and directory",
+			repoUrl: repoUrl,
+			target:  config.RepoTarget{ProjectName: "app", ProjectVersion: "main", Type: config.ProjectTypes{"go"}, Generator: "syft", Directory: &backend},
+			want:    AnalysisPlanEntry{RepoUrl: repoUrl, Commit: commit, Project: "app@main", ProjectType: "go", Directory: "backend", Generator: "syft"},
+		},
+		{
+			name:    "missing directory",
+			repoUrl: repoUrl,
+			target:  config.RepoTarget{ProjectId: "uuid-2", Type: config.ProjectTypes{"js"}, Directory: &frontend},
+			want:    AnalysisPlanEntry{RepoUrl: repoUrl, Commit: commit, Project: "uuid-2", ProjectType: "js", Directory: "frontend", Generator: "cdxgen", Error: "directory frontend does not exist in the repo"},
+		},
+		{
+			name:    "command without executable",
+			repoUrl: repoUrl,
+			target:  config.RepoTarget{ProjectId: "uuid-3", Type: config.ProjectTypes{"go"}, Generator: "command"},
+			want:    AnalysisPlanEntry{RepoUrl: repoUrl, Commit: commit, Project: "uuid-3", ProjectType: "go", Generator: "command", Error: "command: the command generator requires an executable"},
+		},
+		{
+			name:    "repo cannot be cloned",
+			repoUrl: unreachableUrl,
+			target:  config.RepoTarget{ProjectId: "uuid-4", Type: config.ProjectTypes{"go"}},
+			want:    AnalysisPlanEntry{RepoUrl: unreachableUrl, Project: "uuid-4", ProjectType: "go", Generator: "cdxgen", Error: "authentication required"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			marker := installGeneratorTraps(t)
+			cloner := &fakeCloner{path: repoPath, errs: map[string]error{unreachableUrl: fmt.Errorf("authentication required")}}
+			settings := &config.Settings{Repositories: []config.Repo{{Url: tt.repoUrl, Targets: []config.RepoTarget{tt.target}}}}
+
+			entries := PlanAnalysis(settings, cloner)
+
+			if len(entries) != 1 || entries[0] != tt.want {
+				t.Errorf("expected %+v, got %+v", tt.want, entries)
+			}
+			if _, err := os.Stat(marker); err == nil {
+				t.Error("expected no generator to be run")
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"central-cyclone/internal/models"
+	"central-cyclone/internal/upload"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+type Status string
+
+const (
+	StatusCloned   Status = "cloned"
+	StatusAnalyzed Status = "analyzed"
+	StatusUploaded Status = "uploaded"
+	StatusSaved    Status = "saved"
+	StatusFailed   Status = "failed"
+)
+
+// Policies deciding whether a run failed
+const (
+	FailOnAny   = "any"   // Any repo or target failed
+	FailOnAll   = "all"   // Every target failed
+	FailOnNever = "never" // Never fail, only report
+)
+
+// RunReport is the result of an analyze run
+type RunReport struct {
+	StartedAt       time.Time    `json:"startedAt"`
+	DurationSeconds float64      `json:"durationSeconds"`
+	Repos           []RepoResult `json:"repos"`
+}
+
+// RepoResult is the result of a single repository. A repo that could not be cloned has no target results.
+type RepoResult struct {
+	RepoUrl         string         `json:"repoUrl"`
+	Status          Status         `json:"status"`
+	DurationSeconds float64        `json:"durationSeconds"`
+	Error           string         `json:"error,omitempty"`
+	Targets         []TargetResult `json:"targets"`
+}
+
+type TargetResult struct {
+	ProjectId       string  `json:"projectId,omitempty"`
+	ProjectName     string  `json:"projectName,omitempty"`    // Set if the project is identified by name and version
+	ProjectVersion  string  `json:"projectVersion,omitempty"` // Set if the project is identified by name and version
+	ProjectType     string  `json:"projectType"`
+	Directory       string  `json:"directory,omitempty"`
+	Status          Status  `json:"status"`
+	DurationSeconds float64 `json:"durationSeconds"`
+	Error           string  `json:"error,omitempty"`
+	// Findings and metrics of the project, only set if the upload waited for DependencyTrack to process the SBOM
+	Upload *upload.UploadResult `json:"upload,omitempty"`
+}
+
+// Project describes the project of the target, its id or name@version
+func (t TargetResult) Project() string {
+	return models.ProjectLabel(t.ProjectId, t.ProjectName, t.ProjectVersion)
+}
+
+// Counts returns the number of failed and total units. A repo that could not be cloned counts as one failed unit,
+// otherwise every target counts as one unit.
+func (r RunReport) Counts() (failed int, total int) {
+	for _, repo := range r.Repos {
+		if repo.Status == StatusFailed && len(repo.Targets) == 0 {
+			failed++
+			total++
+			continue
+		}
+		for _, target := range repo.Targets {
+			if target.Status == StatusFailed {
+				failed++
+			}
+			total++
+		}
+	}
+	return failed, total
+}
+
+// ValidateFailOn checks that the policy is one of FailOnAny, FailOnAll or FailOnNever
+func ValidateFailOn(policy string) error {
+	switch policy {
+	case FailOnAny, FailOnAll, FailOnNever:
+		return nil
+	default:
+		return fmt.Errorf("unknown fail-on policy '%s', expected '%s', '%s' or '%s'", policy, FailOnAny, FailOnAll, FailOnNever)
+	}
+}
+
+// Failed decides with the given policy, whether the run failed
+func (r RunReport) Failed(policy string) (bool, error) {
+	if err := ValidateFailOn(policy); err != nil {
+		return false, err
+	}
+	failed, total := r.Counts()
+	switch policy {
+	case FailOnAny:
+		return failed > 0, nil
+	case FailOnAll:
+		return total > 0 && failed == total, nil
+	default:
+		return false, nil
+	}
+}
+
+// WriteJSON writes the report as JSON file
+func (r RunReport) WriteJSON(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal report: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write report %s: %w", path, err)
+	}
+	return nil
+}
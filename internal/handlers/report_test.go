@@ -0,0 +1,72 @@
+package handlers
+
+import "testing"
+
+func TestRunReport_Failed(t *testing.T) {
+	partiallyFailed := RunReport{Repos: []RepoResult{
+		{RepoUrl: "a", Status: StatusFailed, Targets: []TargetResult{{Status: StatusSaved}, {Status: StatusFailed}}},
+		{RepoUrl: "b", Status: StatusCloned, Targets: []TargetResult{{Status: StatusUploaded}}},
+	}}
+	allFailed := RunReport{Repos: []RepoResult{
+		{RepoUrl: "a", Status: StatusFailed, Error: "clone failed", Targets: []TargetResult{}},
+		{RepoUrl: "b", Status: StatusFailed, Targets: []TargetResult{{Status: StatusFailed}, {Status: StatusFailed}}},
+	}}
+	succeeded := RunReport{Repos: []RepoResult{
+		{RepoUrl: "a", Status: StatusCloned, Targets: []TargetResult{{Status: StatusSaved}}},
+	}}
+
+	tests := []struct {
+		name   string
+		report RunReport
+		policy string
+		want   bool
+	}{
+		{name: "any with partial failure", report: partiallyFailed, policy: FailOnAny, want: true},
+		{name: "all with partial failure", report: partiallyFailed, policy: FailOnAll, want: false},
+		{name: "all with complete failure", report: allFailed, policy: FailOnAll, want: true},
+		{name: "never with complete failure", report: allFailed, policy: FailOnNever, want: false},
+		{name: "any without failure", report: succeeded, policy: FailOnAny, want: false},
+		{name: "all without targets", report: RunReport{}, policy: FailOnAll, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.report.Failed(tt.policy)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	if _, err := succeeded.Failed("sometimes"); err == nil {
+		t.Error("expected error for unknown policy")
+	}
+}
+
+func TestValidateFailOn(t *testing.T) {
+	for _, policy := range []string{FailOnAny, FailOnAll, FailOnNever} {
+		if err := ValidateFailOn(policy); err != nil {
+			t.Errorf("expected policy %s to be valid, got %v", policy, err)
+		}
+	}
+	for _, policy := range []string{"", "sometimes", "ANY"} {
+		if err := ValidateFailOn(policy); err == nil {
+			t.Errorf("expected error for policy %q", policy)
+		}
+	}
+}
+
+func TestRunReport_Counts(t *testing.T) {
+	report := RunReport{Repos: []RepoResult{
+		{RepoUrl: "a", Status: StatusFailed, Error: "clone failed", Targets: []TargetResult{}},
+		{RepoUrl: "b", Status: StatusFailed, Targets: []TargetResult{{Status: StatusSaved}, {Status: StatusFailed}, {Status: StatusFailed}}},
+	}}
+
+	failed, total := report.Counts()
+	if failed != 3 || total != 4 {
+		t.Errorf("expected 3 of 4 failed, got %d of %d", failed, total)
+	}
+}
@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "central_cyclone"
+
+var (
+	// AnalysisDuration tracks the duration of SBOM generator runs by project type and result
+	AnalysisDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "analysis_duration_seconds",
+		Help:      "Duration of SBOM generator runs.",
+		Buckets:   []float64{5, 15, 30, 60, 120, 300, 600, 1200, 1800},
+	}, []string{"project_type", "result"})
+
+	// AnalysisFailures counts failed SBOM generator runs by project type
+	AnalysisFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "analysis_failures_total",
+		Help:      "Number of failed SBOM generator runs.",
+	}, []string{"project_type"})
+
+	// Uploads counts SBOM uploads by the returned HTTP status code, "error" if no response was received
+	Uploads = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "uploads_total",
+		Help:      "Number of SBOM uploads to DependencyTrack by HTTP status code.",
+	}, []string{"status_code"})
+
+	// UploadDuration tracks the duration of SBOM uploads
+	UploadDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "upload_duration_seconds",
+		Help:      "Duration of SBOM uploads to DependencyTrack.",
+		Buckets:   prometheus.DefBuckets,
+	})
+
+	// GitDuration tracks the duration of git operations, e.g. clone or fetch, by operation and result
+	GitDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "git_duration_seconds",
+		Help:      "Duration of git clone and fetch operations.",
+		Buckets:   []float64{0.5, 1, 2.5, 5, 10, 30, 60, 120, 300},
+	}, []string{"operation", "result"})
+
+	// ReconcileIterations counts the runs of the GitOps reconcile loop
+	ReconcileIterations = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reconcile_iterations_total",
+		Help:      "Number of GitOps reconcile loop iterations.",
+	})
+
+	// UnhandledAppStates is the number of app versions not yet handled in GitOps mode
+	UnhandledAppStates = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "unhandled_app_states",
+		Help:      "Number of GitOps app versions that are not handled yet.",
+	})
+)
+
+var registry = prometheus.NewRegistry()
+
+func init() {
+	registry.MustRegister(
+		AnalysisDuration,
+		AnalysisFailures,
+		Uploads,
+		UploadDuration,
+		GitDuration,
+		ReconcileIterations,
+		UnhandledAppStates,
+	)
+}
+
+// Result returns the label value of the result label for the given error
+func Result(err error) string {
+	if err != nil {
+		return "failure"
+	}
+	return "success"
+}
+
+// Handler serves all metrics in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// WriteTextfile writes all metrics to the given file, e.g. for the node-exporter textfile collector.
+// The file is replaced atomically.
+func WriteTextfile(path string) error {
+	if err := prometheus.WriteToTextfile(path, registry); err != nil {
+		return fmt.Errorf("failed to write metrics to %s: %w", path, err)
+	}
+	return nil
+}
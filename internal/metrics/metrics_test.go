@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteTextfile(t *testing.T) {
+	Uploads.WithLabelValues("200").Inc()
+	AnalysisDuration.WithLabelValues("node", Result(nil)).Observe(12)
+
+	path := filepath.Join(t.TempDir(), "central-cyclone.prom")
+	if err := WriteTextfile(path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read textfile: %v", err)
+	}
+	content := string(data)
+
+	for _, expected := range []string{
+		`central_cyclone_uploads_total{status_code="200"}`,
+		`central_cyclone_analysis_duration_seconds_count{project_type="node",result="success"} 1`,
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("expected textfile to contain %q, got:\n%s", expected, content)
+		}
+	}
+}
+
+func TestResult(t *testing.T) {
+	if Result(nil) != "success" {
+		t.Errorf("expected success for nil error")
+	}
+	if Result(errors.New("failed")) != "failure" {
+		t.Errorf("expected failure for error")
+	}
+}
@@ -1,7 +1,30 @@
 package models
 
 type Sbom struct {
-	ProjectId   string `json:"projectId"`
-	ProjectType string `json:"projectType"`
-	Data        string `json:"data"`
+	ProjectId      string         `json:"projectId"`
+	ProjectName    string         `json:"projectName,omitempty"`    // Identifies the project together with the version, if no id is set
+	ProjectVersion string         `json:"projectVersion,omitempty"` // Identifies the project together with the name, if no id is set
+	ProjectType    string         `json:"projectType"`
+	AutoCreate     bool           `json:"autoCreate,omitempty"` // Creates the project on upload, if it does not exist
+	Parent         *ProjectParent `json:"parent,omitempty"`     // Optional parent of a project created on upload
+	Tags           []string       `json:"tags,omitempty"`       // Optional tags of a project created on upload
+	Generator      string         `json:"generator,omitempty"`
+	RepoUrl        string         `json:"repoUrl,omitempty"`
+	Commit         string         `json:"commit,omitempty"`
+	Data           string         `json:"data"`
+}
+
+// ProjectParent identifies the parent project either by its UUID or by name and optional version
+type ProjectParent struct {
+	Uuid    string `json:"uuid,omitempty"`
+	Name    string `json:"name,omitempty"`
+	Version string `json:"version,omitempty"`
+}
+
+// ProjectLabel describes a project identified either by its id or by name and version, e.g. for logs and tables
+func ProjectLabel(id, name, version string) string {
+	if id != "" || name == "" {
+		return id
+	}
+	return name + "@" + version
 }
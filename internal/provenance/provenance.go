@@ -0,0 +1,128 @@
+package provenance
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PropertyPrefix namespaces the metadata properties added by central-cyclone
+const PropertyPrefix = "central-cyclone:"
+
+// Provenance describes where a SBOM comes from. Empty values are not added to the SBOM.
+type Provenance struct {
+	RepoUrl       string
+	Commit        string // Resolved commit hash of the analyzed revision
+	Version       string // Git tag or deployed version
+	Environment   string // GitOps environment
+	GitOpsRepoUrl string
+	GitOpsCommit  string // Commit of the GitOps repo the version was read from
+	ToolVersion   string // Version of central-cyclone
+	Timestamp     time.Time
+}
+
+// properties returns the provenance as CycloneDX properties in a stable order
+func (p Provenance) properties() []map[string]any {
+	var properties []map[string]any
+	add := func(name, value string) {
+		if value != "" {
+			properties = append(properties, map[string]any{"name": PropertyPrefix + name, "value": value})
+		}
+	}
+	add("repo.url", p.RepoUrl)
+	add("repo.commit", p.Commit)
+	add("version", p.Version)
+	add("gitops.environment", p.Environment)
+	add("gitops.repo.url", p.GitOpsRepoUrl)
+	add("gitops.repo.commit", p.GitOpsCommit)
+	add("tool.version", p.ToolVersion)
+	if !p.Timestamp.IsZero() {
+		add("timestamp", p.Timestamp.UTC().Format(time.RFC3339))
+	}
+	return properties
+}
+
+// Apply adds the provenance to the metadata.properties of the CycloneDX JSON document. The repo is added
+// as VCS external reference of the metadata component, or of the BOM if it has no metadata component.
+// Properties and references added by an earlier call are replaced, all other content is kept.
+func Apply(data string, p Provenance) (string, error) {
+	decoder := json.NewDecoder(strings.NewReader(data))
+	decoder.UseNumber()
+	var bom map[string]any
+	if err := decoder.Decode(&bom); err != nil {
+		return "", fmt.Errorf("failed to parse sbom: %w", err)
+	}
+
+	metadata, ok := bom["metadata"].(map[string]any)
+	if !ok {
+		metadata = make(map[string]any)
+		bom["metadata"] = metadata
+	}
+	if properties := append(withoutOwnProperties(metadata["properties"]), toAny(p.properties())...); len(properties) > 0 {
+		metadata["properties"] = properties
+	} else {
+		delete(metadata, "properties")
+	}
+
+	if p.RepoUrl != "" {
+		target := bom
+		if component, ok := metadata["component"].(map[string]any); ok {
+			target = component
+		}
+		target["externalReferences"] = append(withoutVcsReference(target["externalReferences"], p.RepoUrl), vcsReference(p))
+	}
+
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(bom); err != nil {
+		return "", fmt.Errorf("failed to serialize sbom: %w", err)
+	}
+	return strings.TrimSuffix(buffer.String(), "\n"), nil
+}
+
+func vcsReference(p Provenance) map[string]any {
+	reference := map[string]any{"type": "vcs", "url": p.RepoUrl}
+	if p.Commit != "" {
+		reference["comment"] = "Commit " + p.Commit
+	}
+	return reference
+}
+
+// withoutOwnProperties returns the existing properties without the ones added by central-cyclone
+func withoutOwnProperties(existing any) []any {
+	var kept []any
+	properties, _ := existing.([]any)
+	for _, property := range properties {
+		fields, _ := property.(map[string]any)
+		if name, _ := fields["name"].(string); strings.HasPrefix(name, PropertyPrefix) {
+			continue
+		}
+		kept = append(kept, property)
+	}
+	return kept
+}
+
+// withoutVcsReference returns the existing external references without the VCS reference of the repo
+func withoutVcsReference(existing any, repoUrl string) []any {
+	var kept []any
+	references, _ := existing.([]any)
+	for _, reference := range references {
+		fields, _ := reference.(map[string]any)
+		if fields["type"] == "vcs" && fields["url"] == repoUrl {
+			continue
+		}
+		kept = append(kept, reference)
+	}
+	return kept
+}
+
+func toAny[T any](values []T) []any {
+	result := make([]any, len(values))
+	for i, value := range values {
+		result[i] = value
+	}
+	return result
+}
@@ -0,0 +1,110 @@
+package provenance
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApply(t *testing.T) {
+	sbom := `{"bomFormat":"CycloneDX","specVersion":"1.6","serialNumber":"urn:uuid:1","version":1,` +
+		`"metadata":{"component":{"name":"app","externalReferences":[{"type":"website","url":"https://example.com"}]},` +
+		`"properties":[{"name":"cdx:reproducible","value":"true"}]},"components":[{"name":"lib","version":"1.2.3"}]}`
+	p := Provenance{
+		RepoUrl:       "https://github.com/example/app.git",
+		Commit:        "0123abc",
+		Version:       "v1.2.3",
+		Environment:   "prod",
+		GitOpsRepoUrl: "https://github.com/example/gitops.git",
+		GitOpsCommit:  "4567def",
+		ToolVersion:   "v0.9.0",
+		Timestamp:     time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	// Applied twice, such that the second call replaces the provenance of the first one
+	enriched, err := Apply(sbom, Provenance{RepoUrl: p.RepoUrl, Commit: "outdated"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	enriched, err = Apply(enriched, p)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var bom struct {
+		SpecVersion string `json:"specVersion"`
+		Version     int    `json:"version"`
+		Metadata    struct {
+			Component struct {
+				ExternalReferences []map[string]string `json:"externalReferences"`
+			} `json:"component"`
+			Properties []map[string]string `json:"properties"`
+		} `json:"metadata"`
+		Components []map[string]string `json:"components"`
+	}
+	if err := json.Unmarshal([]byte(enriched), &bom); err != nil {
+		t.Fatalf("invalid sbom: %v", err)
+	}
+
+	if bom.SpecVersion != "1.6" || bom.Version != 1 || len(bom.Components) != 1 || bom.Components[0]["version"] != "1.2.3" {
+		t.Errorf("expected existing content to be kept, got %s", enriched)
+	}
+
+	properties := make(map[string]string)
+	for _, property := range bom.Metadata.Properties {
+		if _, exists := properties[property["name"]]; exists {
+			t.Errorf("duplicate property %s", property["name"])
+		}
+		properties[property["name"]] = property["value"]
+	}
+	want := map[string]string{
+		"cdx:reproducible":                   "true",
+		"central-cyclone:repo.url":           p.RepoUrl,
+		"central-cyclone:repo.commit":        p.Commit,
+		"central-cyclone:version":            p.Version,
+		"central-cyclone:gitops.environment": p.Environment,
+		"central-cyclone:gitops.repo.url":    p.GitOpsRepoUrl,
+		"central-cyclone:gitops.repo.commit": p.GitOpsCommit,
+		"central-cyclone:tool.version":       p.ToolVersion,
+		"central-cyclone:timestamp":          "2026-01-02T03:04:05Z",
+	}
+	if len(properties) != len(want) {
+		t.Errorf("expected %d properties, got %v", len(want), properties)
+	}
+	for name, value := range want {
+		if properties[name] != value {
+			t.Errorf("expected property %s=%s, got %q", name, value, properties[name])
+		}
+	}
+
+	references := bom.Metadata.Component.ExternalReferences
+	if len(references) != 2 || references[0]["type"] != "website" {
+		t.Fatalf("expected website and vcs reference, got %v", references)
+	}
+	if references[1]["type"] != "vcs" || references[1]["url"] != p.RepoUrl || references[1]["comment"] != "Commit 0123abc" {
+		t.Errorf("unexpected vcs reference: %v", references[1])
+	}
+}
+
+func TestApply_WithoutMetadataComponent(t *testing.T) {
+	enriched, err := Apply(`{"bomFormat":"CycloneDX"}`, Provenance{RepoUrl: "https://github.com/example/app.git", Environment: "dev"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{
+		`"externalReferences":[{"type":"vcs","url":"https://github.com/example/app.git"}]`,
+		`"properties":[{"name":"central-cyclone:repo.url","value":"https://github.com/example/app.git"},{"name":"central-cyclone:gitops.environment","value":"dev"}]`,
+	} {
+		if !strings.Contains(enriched, expected) {
+			t.Errorf("expected %s in %s", expected, enriched)
+		}
+	}
+}
+
+func TestApply_InvalidSbom(t *testing.T) {
+	if _, err := Apply("not json", Provenance{}); err == nil {
+		t.Error("expected error for invalid sbom")
+	}
+}
@@ -0,0 +1,114 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	ImagePartTag    = "tag"
+	ImagePartDigest = "digest"
+)
+
+// ImageReference is a parsed container image reference like registry/basket-backend:1.4.2@sha256:...
+type ImageReference struct {
+	Name   string
+	Tag    string
+	Digest string
+}
+
+// ParseImageReference splits an image reference into its name, tag and digest
+func ParseImageReference(reference string) (ImageReference, error) {
+	reference = strings.Trim(strings.TrimSpace(reference), `"'`)
+	if reference == "" {
+		return ImageReference{}, fmt.Errorf("image reference is empty")
+	}
+
+	var parsed ImageReference
+	name, digest, hasDigest := strings.Cut(reference, "@")
+	if hasDigest {
+		if digest == "" {
+			return ImageReference{}, fmt.Errorf("image reference %s has an empty digest", reference)
+		}
+		parsed.Digest = digest
+	}
+
+	// A colon after the last slash separates the tag, a colon before it belongs to the registry port
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		parsed.Tag = name[i+1:]
+		name = name[:i]
+	}
+	if name == "" {
+		return ImageReference{}, fmt.Errorf("image reference %s has no name", reference)
+	}
+	parsed.Name = name
+
+	return parsed, nil
+}
+
+// ImageRevisionExtractor decorates another extractor, whose value is a full image reference.
+// It takes the tag or digest of the image and optionally maps it to a git revision.
+type ImageRevisionExtractor struct {
+	inner           ValueExtractor
+	part            string
+	stripPrefix     string
+	revisionPattern *regexp.Regexp
+}
+
+// NewImageRevisionExtractor creates the decorator. The part is either tag (default) or digest.
+// The stripPrefix is removed from the value, afterwards the optional revisionPattern is applied,
+// whose first capture group is used as revision.
+func NewImageRevisionExtractor(inner ValueExtractor, part, stripPrefix, revisionPattern string) (*ImageRevisionExtractor, error) {
+	if part == "" {
+		part = ImagePartTag
+	}
+	if part != ImagePartTag && part != ImagePartDigest {
+		return nil, fmt.Errorf("unknown image part '%s', expected '%s' or '%s'", part, ImagePartTag, ImagePartDigest)
+	}
+
+	extractor := &ImageRevisionExtractor{inner: inner, part: part, stripPrefix: stripPrefix}
+	if revisionPattern != "" {
+		pattern, err := regexp.Compile(revisionPattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid revision pattern '%s': %w", revisionPattern, err)
+		}
+		if pattern.NumSubexp() < 1 {
+			return nil, fmt.Errorf("revision pattern '%s' requires a capture group", revisionPattern)
+		}
+		extractor.revisionPattern = pattern
+	}
+	return extractor, nil
+}
+
+func (e *ImageRevisionExtractor) ExtractValue(content []byte, yamlPath string) (string, error) {
+	value, err := e.inner.ExtractValue(content, yamlPath)
+	if err != nil {
+		return "", err
+	}
+
+	image, err := ParseImageReference(value)
+	if err != nil {
+		return "", err
+	}
+
+	revision := image.Tag
+	if e.part == ImagePartDigest {
+		revision = image.Digest
+	}
+	if revision == "" {
+		return "", fmt.Errorf("image reference %s has no %s", value, e.part)
+	}
+
+	revision = strings.TrimPrefix(revision, e.stripPrefix)
+
+	if e.revisionPattern != nil {
+		match := e.revisionPattern.FindStringSubmatch(revision)
+		if match == nil || match[1] == "" {
+			return "", fmt.Errorf("%s '%s' of image %s does not match revision pattern '%s'", e.part, revision, image.Name, e.revisionPattern)
+		}
+		revision = match[1]
+	}
+
+	return revision, nil
+}
@@ -0,0 +1,103 @@
+package query
+
+import (
+	"testing"
+)
+
+func TestParseImageReference(t *testing.T) {
+	tests := []struct {
+		reference string
+		want      ImageReference
+		wantError bool
+	}{
+		{reference: "basket-backend:1.4.2", want: ImageReference{Name: "basket-backend", Tag: "1.4.2"}},
+		{reference: "registry:5000/org/basket-backend", want: ImageReference{Name: "registry:5000/org/basket-backend"}},
+		{reference: "registry:5000/org/basket-backend:1.4.2@sha256:abc", want: ImageReference{Name: "registry:5000/org/basket-backend", Tag: "1.4.2", Digest: "sha256:abc"}},
+		{reference: "ghcr.io/org/basket@sha256:abc", want: ImageReference{Name: "ghcr.io/org/basket", Digest: "sha256:abc"}},
+		{reference: `"ghcr.io/org/basket:v1"`, want: ImageReference{Name: "ghcr.io/org/basket", Tag: "v1"}},
+		{reference: "", wantError: true},
+		{reference: "ghcr.io/org/basket@", wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.reference, func(t *testing.T) {
+			got, err := ParseImageReference(tt.reference)
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("expected error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImageRevisionExtractor_ExtractValue(t *testing.T) {
+	content := []byte(`image: registry.example.com/basket-backend:release-v1.4.2@sha256:0123abcd`)
+
+	tests := []struct {
+		name            string
+		part            string
+		stripPrefix     string
+		revisionPattern string
+		want            string
+		wantError       bool
+	}{
+		{name: "tag", want: "release-v1.4.2"},
+		{name: "digest", part: ImagePartDigest, want: "sha256:0123abcd"},
+		{name: "strip prefix", stripPrefix: "release-", want: "v1.4.2"},
+		{name: "revision pattern", revisionPattern: `^release-v(\d+\.\d+\.\d+)$`, want: "1.4.2"},
+		{name: "strip prefix and revision pattern", stripPrefix: "release-", revisionPattern: `^(v\d+)\.`, want: "v1"},
+		{name: "not matching revision pattern", revisionPattern: `^hotfix-(.*)$`, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			extractor, err := NewImageRevisionExtractor(NewYqValueExtractor(), tt.part, tt.stripPrefix, tt.revisionPattern)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got, err := extractor.ExtractValue(content, ".image")
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("expected error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImageRevisionExtractor_MissingTag(t *testing.T) {
+	extractor, err := NewImageRevisionExtractor(NewYqValueExtractor(), ImagePartTag, "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := extractor.ExtractValue([]byte(`image: ghcr.io/org/basket@sha256:abc`), ".image"); err == nil {
+		t.Error("expected error for image without tag")
+	}
+}
+
+func TestNewImageRevisionExtractor_InvalidOptions(t *testing.T) {
+	if _, err := NewImageRevisionExtractor(NewYqValueExtractor(), "label", "", ""); err == nil {
+		t.Error("expected error for unknown image part")
+	}
+	if _, err := NewImageRevisionExtractor(NewYqValueExtractor(), ImagePartTag, "", "("); err == nil {
+		t.Error("expected error for invalid revision pattern")
+	}
+	if _, err := NewImageRevisionExtractor(NewYqValueExtractor(), ImagePartTag, "", "^v.*$"); err == nil {
+		t.Error("expected error for revision pattern without capture group")
+	}
+}
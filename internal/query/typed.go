@@ -0,0 +1,99 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// The typed extractors know where a GitOps tool keeps the deployed version. They build the yq expression
+// themselves and ignore the yamlPath passed to ExtractValue. Files with multiple YAML documents are supported,
+// the version is taken from the single matching resource.
+
+// ArgoApplicationExtractor extracts spec.source.targetRevision of an Argo CD Application.
+// For multi source applications, the targetRevision of the first source is used.
+type ArgoApplicationExtractor struct {
+	yq           *YqValueExtractor
+	resourceName string
+}
+
+// NewArgoApplicationExtractor creates an extractor for Argo CD Applications. The optional resourceName
+// selects the Application by its metadata.name, if the file contains multiple ones.
+func NewArgoApplicationExtractor(resourceName string) *ArgoApplicationExtractor {
+	return &ArgoApplicationExtractor{yq: NewYqValueExtractor(), resourceName: resourceName}
+}
+
+func (e *ArgoApplicationExtractor) ExtractValue(content []byte, _ string) (string, error) {
+	expression := fmt.Sprintf(`select(%s) | (.spec.source.targetRevision // .spec.sources[0].targetRevision)`,
+		resourceSelector("Application", e.resourceName))
+	return extractSingleValue(e.yq, content, expression, "Argo CD Application")
+}
+
+// KustomizeImageExtractor extracts the newTag of an image override in a kustomization.yaml
+type KustomizeImageExtractor struct {
+	yq        *YqValueExtractor
+	imageName string
+}
+
+func NewKustomizeImageExtractor(imageName string) *KustomizeImageExtractor {
+	return &KustomizeImageExtractor{yq: NewYqValueExtractor(), imageName: imageName}
+}
+
+func (e *KustomizeImageExtractor) ExtractValue(content []byte, _ string) (string, error) {
+	if e.imageName == "" {
+		return "", fmt.Errorf("image name cannot be empty")
+	}
+	expression := fmt.Sprintf(`.images[] | select(.name == %s) | .newTag`, strconv.Quote(e.imageName))
+	return extractSingleValue(e.yq, content, expression, fmt.Sprintf("kustomize image '%s'", e.imageName))
+}
+
+// HelmReleaseExtractor extracts spec.chart.spec.version of a Flux HelmRelease
+type HelmReleaseExtractor struct {
+	yq           *YqValueExtractor
+	resourceName string
+}
+
+// NewHelmReleaseExtractor creates an extractor for Flux HelmReleases. The optional resourceName
+// selects the HelmRelease by its metadata.name, if the file contains multiple ones.
+func NewHelmReleaseExtractor(resourceName string) *HelmReleaseExtractor {
+	return &HelmReleaseExtractor{yq: NewYqValueExtractor(), resourceName: resourceName}
+}
+
+func (e *HelmReleaseExtractor) ExtractValue(content []byte, _ string) (string, error) {
+	expression := fmt.Sprintf(`select(%s) | .spec.chart.spec.version`, resourceSelector("HelmRelease", e.resourceName))
+	return extractSingleValue(e.yq, content, expression, "Flux HelmRelease")
+}
+
+// resourceSelector builds a yq condition matching the kind and, if given, the name of a Kubernetes resource
+func resourceSelector(kind, name string) string {
+	selector := fmt.Sprintf(".kind == %s", strconv.Quote(kind))
+	if name != "" {
+		selector += fmt.Sprintf(" and .metadata.name == %s", strconv.Quote(name))
+	}
+	return selector
+}
+
+// extractSingleValue evaluates the expression and ensures that exactly one non-null value was found
+func extractSingleValue(yq *YqValueExtractor, content []byte, expression, description string) (string, error) {
+	value, err := yq.ExtractValue(content, expression)
+	if err != nil {
+		return "", fmt.Errorf("failed to find version of %s: %w", description, err)
+	}
+
+	var values []string
+	for _, line := range strings.Split(value, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && line != "---" && line != "null" {
+			values = append(values, line)
+		}
+	}
+
+	switch len(values) {
+	case 0:
+		return "", fmt.Errorf("no version found for %s", description)
+	case 1:
+		return values[0], nil
+	default:
+		return "", fmt.Errorf("found %d versions for %s, expected exactly one", len(values), description)
+	}
+}
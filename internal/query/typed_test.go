@@ -0,0 +1,176 @@
+package query
+
+import (
+	"testing"
+)
+
+func TestArgoApplicationExtractor_ExtractValue(t *testing.T) {
+	tests := []struct {
+		name         string
+		resourceName string
+		content      string
+		want         string
+		wantError    bool
+	}{
+		{
+			name: "single source application",
+			content: `apiVersion: argoproj.io/v1alpha1
+kind: Application
+metadata:
+  name: basket
+spec:
+  source:
+    repoURL: https://github.com/org/basket
+    targetRevision: v1.2.3`,
+			want: "v1.2.3",
+		},
+		{
+			name: "multi source application",
+			content: `kind: Application
+metadata:
+  name: basket
+spec:
+  sources:
+    - repoURL: https://github.com/org/basket
+      targetRevision: v2.0.0
+    - repoURL: https://github.com/org/values
+      targetRevision: main`,
+			want: "v2.0.0",
+		},
+		{
+			name:         "select application by name in multi document file",
+			resourceName: "order",
+			content: `kind: Application
+metadata:
+  name: basket
+spec:
+  source:
+    targetRevision: v1.0.0
+---
+kind: Application
+metadata:
+  name: order
+spec:
+  source:
+    targetRevision: v3.0.0`,
+			want: "v3.0.0",
+		},
+		{
+			name: "ambiguous multi document file",
+			content: `kind: Application
+metadata:
+  name: basket
+spec:
+  source:
+    targetRevision: v1.0.0
+---
+kind: Application
+metadata:
+  name: order
+spec:
+  source:
+    targetRevision: v3.0.0`,
+			wantError: true,
+		},
+		{
+			name: "no application",
+			content: `kind: Deployment
+metadata:
+  name: basket`,
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewArgoApplicationExtractor(tt.resourceName).ExtractValue([]byte(tt.content), "")
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("expected error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKustomizeImageExtractor_ExtractValue(t *testing.T) {
+	content := []byte(`apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+images:
+  - name: ghcr.io/org/basket-backend
+    newTag: 1.4.2
+  - name: ghcr.io/org/basket-frontend
+    newTag: 2.0.1`)
+
+	tests := []struct {
+		name      string
+		imageName string
+		want      string
+		wantError bool
+	}{
+		{name: "first image", imageName: "ghcr.io/org/basket-backend", want: "1.4.2"},
+		{name: "second image", imageName: "ghcr.io/org/basket-frontend", want: "2.0.1"},
+		{name: "unknown image", imageName: "ghcr.io/org/other", wantError: true},
+		{name: "empty image name", imageName: "", wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewKustomizeImageExtractor(tt.imageName).ExtractValue(content, "")
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("expected error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHelmReleaseExtractor_ExtractValue(t *testing.T) {
+	content := []byte(`apiVersion: v1
+kind: Namespace
+metadata:
+  name: basket
+---
+apiVersion: helm.toolkit.fluxcd.io/v2
+kind: HelmRelease
+metadata:
+  name: basket
+spec:
+  chart:
+    spec:
+      chart: basket
+      version: "0.8.1"`)
+
+	got, err := NewHelmReleaseExtractor("").ExtractValue(content, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "0.8.1" {
+		t.Errorf("got %q, want %q", got, "0.8.1")
+	}
+
+	if _, err := NewHelmReleaseExtractor("other").ExtractValue(content, ""); err == nil {
+		t.Error("expected error for unknown HelmRelease name")
+	}
+}
+
+func TestTypedExtractors_Interface(t *testing.T) {
+	var _ ValueExtractor = (*ArgoApplicationExtractor)(nil)
+	var _ ValueExtractor = (*KustomizeImageExtractor)(nil)
+	var _ ValueExtractor = (*HelmReleaseExtractor)(nil)
+}
@@ -0,0 +1,45 @@
+package server
+
+import (
+	"central-cyclone/internal/gitops"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+)
+
+// StatusProvider provides the readiness and state of the GitOps mode
+type StatusProvider interface {
+	Ready() bool
+	Status() gitops.StatusReport
+}
+
+// RegisterStatusRoutes registers the following routes:
+//   - /healthz: always 200 while the process is serving requests
+//   - /readyz: 200 once the provider is ready, 503 otherwise
+//   - /api/state: the current state as JSON
+func RegisterStatusRoutes(s *Server, provider StatusProvider) {
+	s.Handle("GET /healthz", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeText(w, http.StatusOK, "ok")
+	}))
+
+	s.Handle("GET /readyz", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !provider.Ready() {
+			writeText(w, http.StatusServiceUnavailable, "not ready")
+			return
+		}
+		writeText(w, http.StatusOK, "ok")
+	}))
+
+	s.Handle("GET /api/state", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(provider.Status()); err != nil {
+			slog.Warn("Could not write state response", "error", err)
+		}
+	}))
+}
+
+func writeText(w http.ResponseWriter, status int, text string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(text))
+}
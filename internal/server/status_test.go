@@ -0,0 +1,94 @@
+package server
+
+import (
+	"central-cyclone/internal/gitops"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type fakeStatusProvider struct {
+	ready  bool
+	report gitops.StatusReport
+}
+
+func (f fakeStatusProvider) Ready() bool {
+	return f.ready
+}
+
+func (f fakeStatusProvider) Status() gitops.StatusReport {
+	return f.report
+}
+
+func serve(s *Server, method, path string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	s.mux.ServeHTTP(recorder, httptest.NewRequest(method, path, nil))
+	return recorder
+}
+
+func TestStatusRoutes_Healthz(t *testing.T) {
+	s := NewServer(":0")
+	RegisterStatusRoutes(s, fakeStatusProvider{})
+
+	if resp := serve(s, http.MethodGet, "/healthz"); resp.Code != http.StatusOK {
+		t.Errorf("expected status 200, got %d", resp.Code)
+	}
+}
+
+func TestStatusRoutes_Readyz(t *testing.T) {
+	tests := []struct {
+		name       string
+		ready      bool
+		wantStatus int
+	}{
+		{name: "not ready", ready: false, wantStatus: http.StatusServiceUnavailable},
+		{name: "ready", ready: true, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer(":0")
+			RegisterStatusRoutes(s, fakeStatusProvider{ready: tt.ready})
+
+			if resp := serve(s, http.MethodGet, "/readyz"); resp.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, resp.Code)
+			}
+		})
+	}
+}
+
+func TestStatusRoutes_State(t *testing.T) {
+	report := gitops.StatusReport{
+		Ready: true,
+		GitOpsRepos: []gitops.GitOpsRepoStatus{
+			{
+				Url: "https://github.com/example/gitops.git",
+				Apps: []gitops.AppStatus{
+					{AppName: "app1", Environment: "prod", CurrentVersion: "1.0.0", Handled: false, LastError: "cdxgen failed"},
+				},
+			},
+		},
+	}
+	s := NewServer(":0")
+	RegisterStatusRoutes(s, fakeStatusProvider{ready: true, report: report})
+
+	resp := serve(s, http.MethodGet, "/api/state")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.Code)
+	}
+	if contentType := resp.Header().Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("unexpected content type %q", contentType)
+	}
+
+	var decoded gitops.StatusReport
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(decoded.GitOpsRepos) != 1 || len(decoded.GitOpsRepos[0].Apps) != 1 {
+		t.Fatalf("unexpected response: %+v", decoded)
+	}
+	if app := decoded.GitOpsRepos[0].Apps[0]; app.LastError != "cdxgen failed" || app.CurrentVersion != "1.0.0" {
+		t.Errorf("unexpected app status: %+v", app)
+	}
+}
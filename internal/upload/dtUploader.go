@@ -2,50 +2,85 @@ package upload
 
 import (
 	"bytes"
+	"central-cyclone/internal/metrics"
 	"central-cyclone/internal/models"
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
+	"strconv"
+	"time"
 )
 
 type DependencyTrackUploader struct {
 	serverURL string
 	apiKey    string
+	wait      *waitOptions // Optional, waits for the processing of the BOM if set
 }
 
-func (uploader DependencyTrackUploader) UploadSBOM(ctx context.Context, sbom models.Sbom) error {
+// UploadSBOM uploads the SBOM. If the uploader waits for the processing, the result describes the project afterwards,
+// otherwise it is nil.
+func (uploader DependencyTrackUploader) UploadSBOM(ctx context.Context, sbom models.Sbom) (*UploadResult, error) {
+	token, err := uploader.submitBom(ctx, sbom)
+	if err != nil {
+		return nil, err
+	}
+	if uploader.wait == nil {
+		return nil, nil
+	}
+	return uploader.waitForProcessing(ctx, sbom, token)
+}
+
+// submitBom uploads the SBOM and returns the token of its processing
+func (uploader DependencyTrackUploader) submitBom(ctx context.Context, sbom models.Sbom) (string, error) {
 	url := uploader.serverURL + "/api/v1/bom"
 	encodedSbom, err := getEncodedSbom(sbom)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	req, err := createRequest(url, uploader.apiKey, sbom.ProjectId, encodedSbom)
+	body, err := newBomSubmitRequest(sbom, encodedSbom)
 	if err != nil {
-		return err
+		return "", err
+	}
+
+	req, err := createRequest(url, uploader.apiKey, body)
+	if err != nil {
+		return "", err
 	}
 
 	// Use context for cancellation
 	req = req.WithContext(ctx)
 
+	start := time.Now()
 	client := &http.Client{}
 	resp, err := client.Do(req)
+	metrics.UploadDuration.Observe(time.Since(start).Seconds())
 	if err != nil {
-		return fmt.Errorf("failed to upload SBOM: %v", err)
+		metrics.Uploads.WithLabelValues("error").Inc()
+		return "", fmt.Errorf("failed to upload SBOM: %v", err)
 	}
 	defer resp.Body.Close()
+	metrics.Uploads.WithLabelValues(strconv.Itoa(resp.StatusCode)).Inc()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("upload failed: status %d, body: %s", resp.StatusCode, string(body))
+		return "", fmt.Errorf("upload failed: status %d, body: %s", resp.StatusCode, string(body))
 	}
 
-	slog.Info("⬆️  Uploaded SBOM successfully")
+	slog.Info("⬆️  Uploaded SBOM successfully", "project", models.ProjectLabel(sbom.ProjectId, sbom.ProjectName, sbom.ProjectVersion))
 
-	return nil
+	var response struct {
+		Token string `json:"token"`
+	}
+	// The token is only required to wait for the processing
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil && uploader.wait != nil {
+		return "", fmt.Errorf("upload succeeded, but the response contains no token: %v", err)
+	}
+	return response.Token, nil
 }
 
 func getEncodedSbom(sbom models.Sbom) (string, error) {
@@ -53,8 +88,51 @@ func getEncodedSbom(sbom models.Sbom) (string, error) {
 	return encodedSbom, nil
 }
 
-func createRequest(url, apiKey, projectId, encodedSbom string) (*http.Request, error) {
-	jsonBody := fmt.Appendf(nil, `{"project":"%s", "bom":"%s"}`, projectId, encodedSbom)
+// bomSubmitRequest is the body of PUT /api/v1/bom. The project is identified by its uuid or by name and version.
+type bomSubmitRequest struct {
+	Project        string       `json:"project,omitempty"`
+	ProjectName    string       `json:"projectName,omitempty"`
+	ProjectVersion string       `json:"projectVersion,omitempty"`
+	ProjectTags    []projectTag `json:"projectTags,omitempty"`
+	AutoCreate     bool         `json:"autoCreate,omitempty"`
+	ParentUUID     string       `json:"parentUUID,omitempty"`
+	ParentName     string       `json:"parentName,omitempty"`
+	ParentVersion  string       `json:"parentVersion,omitempty"`
+	Bom            string       `json:"bom"`
+}
+
+type projectTag struct {
+	Name string `json:"name"`
+}
+
+func newBomSubmitRequest(sbom models.Sbom, encodedSbom string) (bomSubmitRequest, error) {
+	request := bomSubmitRequest{Bom: encodedSbom, AutoCreate: sbom.AutoCreate}
+	switch {
+	case sbom.ProjectId != "":
+		request.Project = sbom.ProjectId
+	case sbom.ProjectName != "" && sbom.ProjectVersion != "":
+		request.ProjectName = sbom.ProjectName
+		request.ProjectVersion = sbom.ProjectVersion
+	default:
+		return bomSubmitRequest{}, fmt.Errorf("sbom identifies no project, either a project id or a name and version are required")
+	}
+
+	for _, tag := range sbom.Tags {
+		request.ProjectTags = append(request.ProjectTags, projectTag{Name: tag})
+	}
+	if sbom.Parent != nil {
+		request.ParentUUID = sbom.Parent.Uuid
+		request.ParentName = sbom.Parent.Name
+		request.ParentVersion = sbom.Parent.Version
+	}
+	return request, nil
+}
+
+func createRequest(url, apiKey string, body bomSubmitRequest) (*http.Request, error) {
+	jsonBody, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %v", err)
+	}
 	req, err := http.NewRequest("PUT", url, bytes.NewBuffer(jsonBody))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %v", err)
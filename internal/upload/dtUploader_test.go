@@ -0,0 +1,79 @@
+package upload
+
+import (
+	"central-cyclone/internal/models"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestDependencyTrackUploader_UploadSBOM_RequestBody(t *testing.T) {
+	tests := []struct {
+		name      string
+		sbom      models.Sbom
+		want      map[string]any
+		wantError bool
+	}{
+		{
+			name: "project id",
+			sbom: models.Sbom{ProjectId: "uuid-1", ProjectName: "app", ProjectVersion: "main", Data: "{}"},
+			want: map[string]any{"project": "uuid-1", "bom": "e30="},
+		},
+		{
+			name: "name and version with autoCreate",
+			sbom: models.Sbom{
+				ProjectName:    "app",
+				ProjectVersion: "main",
+				AutoCreate:     true,
+				Parent:         &models.ProjectParent{Name: "platform", Version: "1"},
+				Tags:           []string{"team-a"},
+				Data:           "{}",
+			},
+			want: map[string]any{
+				"projectName":    "app",
+				"projectVersion": "main",
+				"autoCreate":     true,
+				"parentName":     "platform",
+				"parentVersion":  "1",
+				"projectTags":    []any{map[string]any{"name": "team-a"}},
+				"bom":            "e30=",
+			},
+		},
+		{name: "no project", sbom: models.Sbom{ProjectName: "app", Data: "{}"}, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got map[string]any
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPut || r.URL.Path != "/api/v1/bom" || r.Header.Get("X-Api-Key") != "key" {
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+				}
+				body, _ := io.ReadAll(r.Body)
+				json.Unmarshal(body, &got)
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			uploader := DependencyTrackUploader{serverURL: server.URL, apiKey: "key"}
+			_, err := uploader.UploadSBOM(context.Background(), tt.sbom)
+
+			if tt.wantError {
+				if err == nil {
+					t.Error("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected body %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package upload
+
+import (
+	"central-cyclone/internal/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const defaultPollInterval = 5 * time.Second
+
+type waitOptions struct {
+	timeout      time.Duration
+	pollInterval time.Duration
+}
+
+// UploadResult is the state of the project in DependencyTrack after the uploaded BOM was processed
+type UploadResult struct {
+	ProjectUuid string         `json:"projectUuid"`
+	Findings    FindingCounts  `json:"findings"` // Unsuppressed findings of the project
+	Metrics     ProjectMetrics `json:"metrics"`
+}
+
+// FindingCounts are the number of findings per severity
+type FindingCounts struct {
+	Critical   int `json:"critical"`
+	High       int `json:"high"`
+	Medium     int `json:"medium"`
+	Low        int `json:"low"`
+	Info       int `json:"info"`
+	Unassigned int `json:"unassigned"`
+}
+
+// Total returns the number of findings of all severities
+func (c FindingCounts) Total() int {
+	return c.Critical + c.High + c.Medium + c.Low + c.Info + c.Unassigned
+}
+
+// ProjectMetrics are the current metrics of the project as calculated by DependencyTrack
+type ProjectMetrics struct {
+	Components            int     `json:"components"`
+	Vulnerabilities       int     `json:"vulnerabilities"`
+	PolicyViolationsTotal int     `json:"policyViolationsTotal"`
+	InheritedRiskScore    float64 `json:"inheritedRiskScore"`
+}
+
+// calculatedMetrics are the project metrics with the time of their calculation in epoch milliseconds
+type calculatedMetrics struct {
+	ProjectMetrics
+	LastOccurrence int64 `json:"lastOccurrence"`
+}
+
+type finding struct {
+	Vulnerability struct {
+		Severity string `json:"severity"`
+	} `json:"vulnerability"`
+}
+
+// waitForProcessing polls the event token until DependencyTrack finished processing the BOM,
+// then fetches the findings and the recalculated metrics of the project
+func (uploader DependencyTrackUploader) waitForProcessing(ctx context.Context, sbom models.Sbom, token string) (*UploadResult, error) {
+	project := models.ProjectLabel(sbom.ProjectId, sbom.ProjectName, sbom.ProjectVersion)
+	if token == "" {
+		return nil, fmt.Errorf("upload of %s succeeded, but the response contains no token to wait for", project)
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, uploader.wait.timeout)
+	defer cancel()
+
+	slog.Info("⏳ Waiting for DependencyTrack to process the SBOM", "project", project, "token", token)
+	if err := uploader.pollToken(waitCtx, token); err != nil {
+		if ctx.Err() == nil && waitCtx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("upload of %s succeeded, but its processing did not finish within %s", project, uploader.wait.timeout)
+		}
+		return nil, fmt.Errorf("upload of %s succeeded, but waiting for its processing failed: %w", project, err)
+	}
+
+	projectUuid := sbom.ProjectId
+	if projectUuid == "" {
+		var err error
+		projectUuid, err = uploader.lookupProject(ctx, sbom.ProjectName, sbom.ProjectVersion)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := &UploadResult{ProjectUuid: projectUuid}
+	var findings []finding
+	if err := uploader.getJSON(ctx, "/api/v1/finding/project/"+url.PathEscape(projectUuid), &findings); err != nil {
+		return nil, fmt.Errorf("failed to get findings of %s: %w", project, err)
+	}
+	result.Findings = countFindings(findings)
+
+	metrics, err := uploader.refreshMetrics(waitCtx, projectUuid)
+	if err != nil {
+		if ctx.Err() == nil && waitCtx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("upload of %s succeeded, but its metrics were not recalculated within %s", project, uploader.wait.timeout)
+		}
+		return nil, fmt.Errorf("failed to get metrics of %s: %w", project, err)
+	}
+	result.Metrics = metrics
+
+	slog.Info("✅ DependencyTrack processed the SBOM", "project", project, "findings", result.Findings.Total(), "policyViolations", result.Metrics.PolicyViolationsTotal)
+	return result, nil
+}
+
+// pollToken returns once the event of the token is no longer processing or the context is done
+func (uploader DependencyTrackUploader) pollToken(ctx context.Context, token string) error {
+	ticker := time.NewTicker(uploader.wait.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		var status struct {
+			Processing bool `json:"processing"`
+		}
+		if err := uploader.getJSON(ctx, "/api/v1/event/token/"+url.PathEscape(token), &status); err != nil {
+			return err
+		}
+		if !status.Processing {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// refreshMetrics triggers a recalculation of the project metrics and polls them until it is done. DependencyTrack
+// calculates metrics asynchronously, such that the current metrics may still describe the previous BOM.
+func (uploader DependencyTrackUploader) refreshMetrics(ctx context.Context, projectUuid string) (ProjectMetrics, error) {
+	metricsPath := "/api/v1/metrics/project/" + url.PathEscape(projectUuid)
+	var previous calculatedMetrics
+	// Fails if the project has no metrics yet, e.g. if it was just created
+	if err := uploader.getJSON(ctx, metricsPath+"/current", &previous); err != nil {
+		slog.Debug("No current metrics before the refresh", "project", projectUuid, "error", err)
+	}
+	if err := uploader.getJSON(ctx, metricsPath+"/refresh", nil); err != nil {
+		return ProjectMetrics{}, fmt.Errorf("failed to refresh metrics: %w", err)
+	}
+
+	ticker := time.NewTicker(uploader.wait.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		var current calculatedMetrics
+		if err := uploader.getJSON(ctx, metricsPath+"/current", &current); err != nil {
+			return ProjectMetrics{}, err
+		}
+		if current.LastOccurrence > previous.LastOccurrence {
+			return current.ProjectMetrics, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ProjectMetrics{}, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (uploader DependencyTrackUploader) lookupProject(ctx context.Context, name, version string) (string, error) {
+	query := url.Values{"name": {name}, "version": {version}}
+	var project struct {
+		Uuid string `json:"uuid"`
+	}
+	if err := uploader.getJSON(ctx, "/api/v1/project/lookup?"+query.Encode(), &project); err != nil {
+		return "", fmt.Errorf("failed to look up project %s@%s: %w", name, version, err)
+	}
+	return project.Uuid, nil
+}
+
+func (uploader DependencyTrackUploader) getJSON(ctx context.Context, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uploader.serverURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("X-Api-Key", uploader.apiKey)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("status %d, body: %s", resp.StatusCode, string(body))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("invalid response: %v", err)
+	}
+	return nil
+}
+
+func countFindings(findings []finding) FindingCounts {
+	var counts FindingCounts
+	for _, f := range findings {
+		switch strings.ToUpper(f.Vulnerability.Severity) {
+		case "CRITICAL":
+			counts.Critical++
+		case "HIGH":
+			counts.High++
+		case "MEDIUM":
+			counts.Medium++
+		case "LOW":
+			counts.Low++
+		case "INFO":
+			counts.Info++
+		default:
+			counts.Unassigned++
+		}
+	}
+	return counts
+}
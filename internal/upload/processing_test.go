@@ -0,0 +1,112 @@
+package upload
+
+import (
+	"central-cyclone/internal/models"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newDependencyTrackServer fakes the endpoints used while waiting. The token is processing for the given number of polls,
+// the metrics are recalculated after the given number of polls following their refresh.
+func newDependencyTrackServer(t *testing.T, processingPolls, metricsPolls int32) *httptest.Server {
+	t.Helper()
+	var polls, refreshes, metricsPollsAfterRefresh atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("PUT /api/v1/bom", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"token":"token-1"}`)
+	})
+	mux.HandleFunc("GET /api/v1/event/token/token-1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"processing":%t}`, polls.Add(1) <= processingPolls)
+	})
+	mux.HandleFunc("GET /api/v1/project/lookup", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("name") != "app" || r.URL.Query().Get("version") != "main" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"uuid":"uuid-1"}`)
+	})
+	mux.HandleFunc("GET /api/v1/finding/project/uuid-1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"vulnerability":{"severity":"CRITICAL"}},{"vulnerability":{"severity":"HIGH"}},{"vulnerability":{"severity":"HIGH"}},{"vulnerability":{"severity":"UNASSIGNED"}}]`)
+	})
+	mux.HandleFunc("GET /api/v1/metrics/project/uuid-1/refresh", func(w http.ResponseWriter, r *http.Request) {
+		refreshes.Add(1)
+	})
+	mux.HandleFunc("GET /api/v1/metrics/project/uuid-1/current", func(w http.ResponseWriter, r *http.Request) {
+		if refreshes.Load() == 0 || metricsPollsAfterRefresh.Add(1) <= metricsPolls {
+			// Metrics of the previous BOM
+			fmt.Fprint(w, `{"components":40,"vulnerabilities":9,"policyViolationsTotal":3,"inheritedRiskScore":30,"lastOccurrence":1000}`)
+			return
+		}
+		fmt.Fprint(w, `{"components":42,"vulnerabilities":4,"policyViolationsTotal":1,"inheritedRiskScore":17.5,"lastOccurrence":2000}`)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestDependencyTrackUploader_UploadSBOM_WaitsForProcessing(t *testing.T) {
+	tests := []struct {
+		name string
+		sbom models.Sbom
+	}{
+		{name: "project id", sbom: models.Sbom{ProjectId: "uuid-1", Data: "{}"}},
+		{name: "name and version", sbom: models.Sbom{ProjectName: "app", ProjectVersion: "main", AutoCreate: true, Data: "{}"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newDependencyTrackServer(t, 2, 2)
+			uploader := DependencyTrackUploader{serverURL: server.URL, apiKey: "key", wait: &waitOptions{timeout: 5 * time.Second, pollInterval: time.Millisecond}}
+
+			result, err := uploader.UploadSBOM(context.Background(), tt.sbom)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			want := UploadResult{
+				ProjectUuid: "uuid-1",
+				Findings:    FindingCounts{Critical: 1, High: 2, Unassigned: 1},
+				Metrics:     ProjectMetrics{Components: 42, Vulnerabilities: 4, PolicyViolationsTotal: 1, InheritedRiskScore: 17.5},
+			}
+			if result == nil || *result != want {
+				t.Errorf("expected %+v, got %+v", want, result)
+			}
+		})
+	}
+}
+
+func TestDependencyTrackUploader_UploadSBOM_WaitTimeout(t *testing.T) {
+	server := newDependencyTrackServer(t, 1000, 0)
+	uploader := DependencyTrackUploader{serverURL: server.URL, apiKey: "key", wait: &waitOptions{timeout: 50 * time.Millisecond, pollInterval: 10 * time.Millisecond}}
+
+	_, err := uploader.UploadSBOM(context.Background(), models.Sbom{ProjectId: "uuid-1", Data: "{}"})
+	if err == nil || !strings.Contains(err.Error(), "did not finish within") {
+		t.Errorf("expected timeout error, got %v", err)
+	}
+}
+
+func TestDependencyTrackUploader_UploadSBOM_MetricsTimeout(t *testing.T) {
+	server := newDependencyTrackServer(t, 0, 1000)
+	uploader := DependencyTrackUploader{serverURL: server.URL, apiKey: "key", wait: &waitOptions{timeout: 50 * time.Millisecond, pollInterval: 10 * time.Millisecond}}
+
+	_, err := uploader.UploadSBOM(context.Background(), models.Sbom{ProjectId: "uuid-1", Data: "{}"})
+	if err == nil || !strings.Contains(err.Error(), "metrics were not recalculated within") {
+		t.Errorf("expected metrics timeout error, got %v", err)
+	}
+}
+
+func TestDependencyTrackUploader_UploadSBOM_WithoutWait(t *testing.T) {
+	server := newDependencyTrackServer(t, 0, 0)
+	uploader := DependencyTrackUploader{serverURL: server.URL, apiKey: "key"}
+
+	result, err := uploader.UploadSBOM(context.Background(), models.Sbom{ProjectId: "uuid-1", Data: "{}"})
+	if err != nil || result != nil {
+		t.Errorf("expected no result and no error, got %+v, %v", result, err)
+	}
+}
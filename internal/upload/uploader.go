@@ -6,16 +6,32 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"time"
 )
 
 type Uploader interface {
-	UploadSBOM(ctx context.Context, sbom models.Sbom) error
+	UploadSBOM(ctx context.Context, sbom models.Sbom) (*UploadResult, error)
 }
 
 func CreateDependencyTrackUploader(settings *config.Settings) (Uploader, error) {
+	return newDependencyTrackUploader(settings)
+}
+
+// CreateWaitingDependencyTrackUploader creates an uploader, which waits up to the timeout for DependencyTrack
+// to process each uploaded BOM and returns the findings and metrics of the project afterwards
+func CreateWaitingDependencyTrackUploader(settings *config.Settings, timeout time.Duration) (Uploader, error) {
+	uploader, err := newDependencyTrackUploader(settings)
+	if err != nil {
+		return nil, err
+	}
+	uploader.wait = &waitOptions{timeout: timeout, pollInterval: defaultPollInterval}
+	return uploader, nil
+}
+
+func newDependencyTrackUploader(settings *config.Settings) (DependencyTrackUploader, error) {
 	apiKey := os.Getenv("DEPENDENCYTRACK_API_KEY")
 	if apiKey == "" {
-		return nil, fmt.Errorf("DEPENDENCYTRACK_API_KEY environment variable is not set")
+		return DependencyTrackUploader{}, fmt.Errorf("DEPENDENCYTRACK_API_KEY environment variable is not set")
 	}
 
 	return DependencyTrackUploader{
@@ -0,0 +1,110 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// verifyGitHub checks the HMAC-SHA256 signature of the payload in the X-Hub-Signature-256 header
+func verifyGitHub(secret []byte, req *http.Request, body []byte) error {
+	signature, ok := strings.CutPrefix(req.Header.Get("X-Hub-Signature-256"), "sha256=")
+	if !ok {
+		return errUnauthorized
+	}
+	received, err := hex.DecodeString(signature)
+	if err != nil {
+		return errUnauthorized
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	if !hmac.Equal(received, mac.Sum(nil)) {
+		return errUnauthorized
+	}
+	return nil
+}
+
+// verifyGitLab compares the secret token GitLab sends in the X-Gitlab-Token header
+func verifyGitLab(secret []byte, req *http.Request, body []byte) error {
+	if !secretEquals(secret, req.Header.Get("X-Gitlab-Token")) {
+		return errUnauthorized
+	}
+	return nil
+}
+
+// verifyAzureDevOps compares the basic authentication password configured in the service hook
+func verifyAzureDevOps(secret []byte, req *http.Request, body []byte) error {
+	_, password, ok := req.BasicAuth()
+	if !ok || !secretEquals(secret, password) {
+		return errUnauthorized
+	}
+	return nil
+}
+
+func secretEquals(secret []byte, received string) bool {
+	return len(secret) > 0 && subtle.ConstantTimeCompare(secret, []byte(received)) == 1
+}
+
+type gitHubPushEvent struct {
+	Repository struct {
+		CloneUrl string `json:"clone_url"`
+		HtmlUrl  string `json:"html_url"`
+		SshUrl   string `json:"ssh_url"`
+	} `json:"repository"`
+}
+
+func parseGitHub(req *http.Request, body []byte) ([]string, error) {
+	if req.Header.Get("X-GitHub-Event") != "push" {
+		return nil, errIgnoredEvent
+	}
+	var event gitHubPushEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("failed to parse GitHub push event: %w", err)
+	}
+	return []string{event.Repository.CloneUrl, event.Repository.HtmlUrl, event.Repository.SshUrl}, nil
+}
+
+type gitLabPushEvent struct {
+	Project struct {
+		GitHttpUrl string `json:"git_http_url"`
+		WebUrl     string `json:"web_url"`
+		GitSshUrl  string `json:"git_ssh_url"`
+	} `json:"project"`
+}
+
+func parseGitLab(req *http.Request, body []byte) ([]string, error) {
+	if req.Header.Get("X-Gitlab-Event") != "Push Hook" {
+		return nil, errIgnoredEvent
+	}
+	var event gitLabPushEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("failed to parse GitLab push event: %w", err)
+	}
+	return []string{event.Project.GitHttpUrl, event.Project.WebUrl, event.Project.GitSshUrl}, nil
+}
+
+type azureDevOpsPushEvent struct {
+	EventType string `json:"eventType"`
+	Resource  struct {
+		Repository struct {
+			RemoteUrl string `json:"remoteUrl"`
+		} `json:"repository"`
+	} `json:"resource"`
+}
+
+func parseAzureDevOps(req *http.Request, body []byte) ([]string, error) {
+	var event azureDevOpsPushEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("failed to parse Azure DevOps push event: %w", err)
+	}
+	if event.EventType != "git.push" {
+		return nil, errIgnoredEvent
+	}
+	return []string{event.Resource.Repository.RemoteUrl}, nil
+}
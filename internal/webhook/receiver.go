@@ -0,0 +1,111 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+)
+
+const maxPayloadSize = 10 << 20
+
+var (
+	errUnauthorized = errors.New("invalid webhook signature")
+	errIgnoredEvent = errors.New("event is not a push")
+)
+
+// Receiver accepts push webhooks of GitHub, GitLab and Azure DevOps and triggers
+// a reconcile for the matching GitOps repo.
+type Receiver struct {
+	secret   []byte
+	repoUrls map[string]string // Key: normalized URL, Value: URL as configured
+	trigger  func(repoUrl string)
+}
+
+// verifyFunc checks the authenticity of a request using the shared secret
+type verifyFunc func(secret []byte, req *http.Request, body []byte) error
+
+// parseFunc returns all URLs of the pushed repository found in the payload
+type parseFunc func(req *http.Request, body []byte) ([]string, error)
+
+func NewReceiver(secret string, repoUrls []string, trigger func(repoUrl string)) *Receiver {
+	normalizedUrls := make(map[string]string)
+	for _, repoUrl := range repoUrls {
+		normalizedUrls[NormalizeRepoUrl(repoUrl)] = repoUrl
+	}
+	return &Receiver{
+		secret:   []byte(secret),
+		repoUrls: normalizedUrls,
+		trigger:  trigger,
+	}
+}
+
+func (r *Receiver) GitHubHandler() http.Handler {
+	return r.handler("github", verifyGitHub, parseGitHub)
+}
+
+func (r *Receiver) GitLabHandler() http.Handler {
+	return r.handler("gitlab", verifyGitLab, parseGitLab)
+}
+
+func (r *Receiver) AzureDevOpsHandler() http.Handler {
+	return r.handler("azure-devops", verifyAzureDevOps, parseAzureDevOps)
+}
+
+func (r *Receiver) handler(provider string, verify verifyFunc, parse parseFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(http.MaxBytesReader(w, req.Body, maxPayloadSize))
+		if err != nil {
+			http.Error(w, "could not read payload", http.StatusBadRequest)
+			return
+		}
+
+		if err := verify(r.secret, req, body); err != nil {
+			slog.Warn("Rejected webhook", "provider", provider, "error", err)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		urls, err := parse(req, body)
+		if errors.Is(err, errIgnoredEvent) {
+			slog.Debug("Ignored webhook event", "provider", provider)
+			writeText(w, http.StatusOK, "ignored")
+			return
+		}
+		if err != nil {
+			slog.Warn("Could not parse webhook payload", "provider", provider, "error", err)
+			http.Error(w, "invalid payload", http.StatusBadRequest)
+			return
+		}
+
+		repoUrl, ok := r.matchRepo(urls)
+		if !ok {
+			slog.Info("Ignored webhook for unknown GitOps repo", "provider", provider, "urls", urls)
+			writeText(w, http.StatusOK, "ignored")
+			return
+		}
+
+		slog.Info("🪝 Received push webhook, triggering reconcile", "provider", provider, "repo", repoUrl)
+		r.trigger(repoUrl)
+		writeText(w, http.StatusAccepted, "accepted")
+	})
+}
+
+func (r *Receiver) matchRepo(urls []string) (string, bool) {
+	for _, candidate := range urls {
+		if candidate == "" {
+			continue
+		}
+		if repoUrl, ok := r.repoUrls[NormalizeRepoUrl(candidate)]; ok {
+			return repoUrl, true
+		}
+	}
+	return "", false
+}
+
+func writeText(w http.ResponseWriter, status int, text string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(status)
+	_, _ = fmt.Fprint(w, text)
+}
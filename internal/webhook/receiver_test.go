@@ -0,0 +1,166 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testSecret = "s3cr3t"
+
+type triggerRecorder struct {
+	triggered []string
+}
+
+func (r *triggerRecorder) trigger(repoUrl string) {
+	r.triggered = append(r.triggered, repoUrl)
+}
+
+func newTestReceiver() (*Receiver, *triggerRecorder) {
+	recorder := &triggerRecorder{}
+	receiver := NewReceiver(testSecret, []string{
+		"https://github.com/org/gitops.git",
+		"https://gitlab.com/org/gitops",
+		"https://dev.azure.com/org/project/_git/gitops",
+	}, recorder.trigger)
+	return receiver, recorder
+}
+
+func gitHubSignature(body string) string {
+	mac := hmac.New(sha256.New, []byte(testSecret))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestGitHubHandler(t *testing.T) {
+	pushBody := `{"repository": {"clone_url": "https://github.com/org/gitops.git", "html_url": "https://github.com/org/gitops"}}`
+
+	tests := []struct {
+		name          string
+		event         string
+		body          string
+		signature     string
+		wantStatus    int
+		wantTriggered bool
+	}{
+		{name: "valid push", event: "push", body: pushBody, signature: gitHubSignature(pushBody), wantStatus: http.StatusAccepted, wantTriggered: true},
+		{name: "invalid signature", event: "push", body: pushBody, signature: gitHubSignature("other"), wantStatus: http.StatusUnauthorized},
+		{name: "missing signature", event: "push", body: pushBody, signature: "", wantStatus: http.StatusUnauthorized},
+		{name: "ping event", event: "ping", body: `{}`, signature: gitHubSignature(`{}`), wantStatus: http.StatusOK},
+		{name: "unknown repo", event: "push", body: `{"repository": {"clone_url": "https://github.com/org/other.git"}}`, signature: gitHubSignature(`{"repository": {"clone_url": "https://github.com/org/other.git"}}`), wantStatus: http.StatusOK},
+		{name: "malformed payload", event: "push", body: `{`, signature: gitHubSignature(`{`), wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver, recorder := newTestReceiver()
+			req := httptest.NewRequest(http.MethodPost, "/webhooks/github", strings.NewReader(tt.body))
+			req.Header.Set("X-GitHub-Event", tt.event)
+			if tt.signature != "" {
+				req.Header.Set("X-Hub-Signature-256", tt.signature)
+			}
+			resp := httptest.NewRecorder()
+
+			receiver.GitHubHandler().ServeHTTP(resp, req)
+
+			if resp.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, resp.Code)
+			}
+			if tt.wantTriggered && (len(recorder.triggered) != 1 || recorder.triggered[0] != "https://github.com/org/gitops.git") {
+				t.Errorf("expected reconcile of configured repo, got %v", recorder.triggered)
+			}
+			if !tt.wantTriggered && len(recorder.triggered) != 0 {
+				t.Errorf("expected no reconcile, got %v", recorder.triggered)
+			}
+		})
+	}
+}
+
+func TestGitLabHandler(t *testing.T) {
+	body := `{"project": {"git_http_url": "https://gitlab.com/org/gitops.git"}}`
+
+	tests := []struct {
+		name       string
+		token      string
+		wantStatus int
+	}{
+		{name: "valid token", token: testSecret, wantStatus: http.StatusAccepted},
+		{name: "invalid token", token: "wrong", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver, recorder := newTestReceiver()
+			req := httptest.NewRequest(http.MethodPost, "/webhooks/gitlab", strings.NewReader(body))
+			req.Header.Set("X-Gitlab-Event", "Push Hook")
+			req.Header.Set("X-Gitlab-Token", tt.token)
+			resp := httptest.NewRecorder()
+
+			receiver.GitLabHandler().ServeHTTP(resp, req)
+
+			if resp.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, resp.Code)
+			}
+			if tt.wantStatus == http.StatusAccepted && (len(recorder.triggered) != 1 || recorder.triggered[0] != "https://gitlab.com/org/gitops") {
+				t.Errorf("expected reconcile of configured repo, got %v", recorder.triggered)
+			}
+		})
+	}
+}
+
+func TestAzureDevOpsHandler(t *testing.T) {
+	body := `{"eventType": "git.push", "resource": {"repository": {"remoteUrl": "https://org@dev.azure.com/org/project/_git/gitops"}}}`
+
+	tests := []struct {
+		name       string
+		password   string
+		wantStatus int
+	}{
+		{name: "valid password", password: testSecret, wantStatus: http.StatusAccepted},
+		{name: "invalid password", password: "wrong", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver, recorder := newTestReceiver()
+			req := httptest.NewRequest(http.MethodPost, "/webhooks/azure-devops", strings.NewReader(body))
+			req.SetBasicAuth("central-cyclone", tt.password)
+			resp := httptest.NewRecorder()
+
+			receiver.AzureDevOpsHandler().ServeHTTP(resp, req)
+
+			if resp.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, resp.Code)
+			}
+			if tt.wantStatus == http.StatusAccepted && (len(recorder.triggered) != 1 || recorder.triggered[0] != "https://dev.azure.com/org/project/_git/gitops") {
+				t.Errorf("expected reconcile of configured repo, got %v", recorder.triggered)
+			}
+		})
+	}
+}
+
+func TestNormalizeRepoUrl(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{url: "https://github.com/org/repo.git", want: "github.com/org/repo"},
+		{url: "https://GitHub.com/org/repo/", want: "github.com/org/repo"},
+		{url: "https://token@github.com/org/repo", want: "github.com/org/repo"},
+		{url: "git@github.com:org/repo.git", want: "github.com/org/repo"},
+		{url: "ssh://git@gitlab.com/org/repo.git", want: "gitlab.com/org/repo"},
+		{url: "/tmp/local/repo", want: "/tmp/local/repo"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			if got := NormalizeRepoUrl(tt.url); got != tt.want {
+				t.Errorf("NormalizeRepoUrl(%q) = %q, want %q", tt.url, got, tt.want)
+			}
+		})
+	}
+}
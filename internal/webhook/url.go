@@ -0,0 +1,32 @@
+package webhook
+
+import (
+	"net/url"
+	"strings"
+)
+
+// NormalizeRepoUrl converts a repository URL into a comparable form by dropping credentials,
+// the scheme, a trailing slash and the .git suffix and by lower casing the host.
+// For example https://user@GitHub.com/org/repo.git becomes github.com/org/repo.
+func NormalizeRepoUrl(repoUrl string) string {
+	repoUrl = strings.TrimSpace(repoUrl)
+
+	// scp-like SSH syntax, e.g. git@github.com:org/repo.git
+	if !strings.Contains(repoUrl, "://") {
+		if at := strings.Index(repoUrl, "@"); at != -1 {
+			if host, path, ok := strings.Cut(repoUrl[at+1:], ":"); ok {
+				repoUrl = "ssh://" + host + "/" + path
+			}
+		}
+	}
+
+	parsedUrl, err := url.Parse(repoUrl)
+	if err != nil || parsedUrl.Host == "" {
+		return strings.TrimSuffix(strings.TrimSuffix(repoUrl, "/"), ".git")
+	}
+
+	host := strings.ToLower(parsedUrl.Hostname())
+	path := strings.TrimSuffix(strings.TrimSuffix(parsedUrl.Path, "/"), ".git")
+
+	return host + path
+}
@@ -0,0 +1,155 @@
+package workspace
+
+import (
+	"archive/tar"
+	"central-cyclone/internal/models"
+	"compress/gzip"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// maxBundleEntrySize limits the size of a single SBOM within a bundle
+const maxBundleEntrySize = 512 << 20
+
+// WriteBundle packs all SBOM files of sbomsDir and their signed manifest into a single .tar.gz file, e.g. to transfer them across an air gap
+func WriteBundle(sbomsDir string, bundlePath string) (err error) {
+	entries, err := os.ReadDir(sbomsDir)
+	if err != nil {
+		return fmt.Errorf("failed to read directory '%s': %w", sbomsDir, err)
+	}
+
+	file, err := os.Create(bundlePath)
+	if err != nil {
+		return fmt.Errorf("failed to create bundle %s: %w", bundlePath, err)
+	}
+	defer func() {
+		if closeErr := file.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("failed to write bundle %s: %w", bundlePath, closeErr)
+		}
+	}()
+
+	gzipWriter := gzip.NewWriter(file)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	count := 0
+	for _, entry := range entries {
+		if entry.IsDir() || !(strings.HasSuffix(entry.Name(), ".json") || entry.Name() == manifestName || entry.Name() == manifestName+signatureSuffix) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(sbomsDir, entry.Name()))
+		if err != nil {
+			return fmt.Errorf("failed to read file %s: %w", entry.Name(), err)
+		}
+		header := &tar.Header{Name: entry.Name(), Mode: 0644, Size: int64(len(data)), ModTime: time.Now(), Typeflag: tar.TypeReg}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to add %s to bundle: %w", entry.Name(), err)
+		}
+		if _, err := tarWriter.Write(data); err != nil {
+			return fmt.Errorf("failed to add %s to bundle: %w", entry.Name(), err)
+		}
+		count++
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return fmt.Errorf("failed to write bundle %s: %w", bundlePath, err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return fmt.Errorf("failed to write bundle %s: %w", bundlePath, err)
+	}
+	slog.Info("📦 Wrote SBOM bundle", "path", bundlePath, "files", count)
+	return nil
+}
+
+type BundleReadonlySbomWorkspace struct {
+	path      string
+	verifyKey ed25519.PublicKey
+}
+
+// CreateBundleReadonlySbomWorkspace reads the SBOMs of a bundle written by WriteBundle. If a verify key is given,
+// the bundle requires a signed manifest listing exactly its SBOM files.
+func CreateBundleReadonlySbomWorkspace(path string, verifyKey ed25519.PublicKey) ReadonlySbomWorkspace {
+	return BundleReadonlySbomWorkspace{path: path, verifyKey: verifyKey}
+}
+
+// ReadSboms reads all SBOM envelopes of the bundle with the same rules as LocalReadonlySbomWorkspace
+func (w BundleReadonlySbomWorkspace) ReadSboms() ([]models.Sbom, error) {
+	files, err := w.readEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	sbomFiles := map[string][]byte{}
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		if path.Ext(name) == ".json" {
+			sbomFiles[name] = files[name]
+		} else if name != manifestName && name != manifestName+signatureSuffix {
+			slog.Info("Skipping bundle entry", "entry", name)
+		}
+	}
+
+	if w.verifyKey != nil {
+		if err := verifyManifest(w.verifyKey, files[manifestName], files[manifestName+signatureSuffix], sbomFiles); err != nil {
+			return nil, err
+		}
+	}
+
+	var sboms []models.Sbom
+	for _, name := range slices.Sorted(maps.Keys(sbomFiles)) {
+		sbom, err := decodeSbom(name, sbomFiles[name])
+		if err != nil {
+			return nil, fmt.Errorf("invalid SBOM file %s in bundle: %w", name, err)
+		}
+		sboms = append(sboms, sbom)
+	}
+
+	return sboms, nil
+}
+
+// readEntries reads all regular files of the bundle, as the manifest may be stored before or after the SBOMs
+func (w BundleReadonlySbomWorkspace) readEntries() (map[string][]byte, error) {
+	file, err := os.Open(w.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bundle %s: %w", w.path, err)
+	}
+	defer file.Close()
+
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("bundle %s is not gzip compressed: %w", w.path, err)
+	}
+	defer gzipReader.Close()
+
+	files := map[string][]byte{}
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			return files, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bundle %s: %w", w.path, err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if header.Size > maxBundleEntrySize {
+			return nil, fmt.Errorf("bundle entry %s exceeds %d bytes", header.Name, maxBundleEntrySize)
+		}
+
+		data, err := io.ReadAll(tarReader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bundle entry %s: %w", header.Name, err)
+		}
+		files[header.Name] = data
+	}
+}
@@ -0,0 +1,49 @@
+package workspace
+
+import (
+	"central-cyclone/internal/models"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteBundle_RoundTrip(t *testing.T) {
+	sbomsDir := t.TempDir()
+	want := []models.Sbom{
+		{ProjectId: "uuid-1", ProjectType: "go", Generator: "cdxgen", Data: testBom},
+		{ProjectId: "uuid-2", ProjectType: "java", Generator: "syft", Data: testBom},
+	}
+	for _, sbom := range want {
+		data, _ := encodeSbom(sbom, time.Now())
+		os.WriteFile(DefaultSBOMNamer{}.GenerateSBOMPath(sbomsDir, sbom), data, 0644)
+	}
+	os.WriteFile(filepath.Join(sbomsDir, "notes.txt"), []byte("ignored"), 0644)
+	bundlePath := filepath.Join(t.TempDir(), "sboms.tar.gz")
+
+	if err := WriteBundle(sbomsDir, bundlePath); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := CreateBundleReadonlySbomWorkspace(bundlePath, nil).ReadSboms()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	slices.SortFunc(got, func(a, b models.Sbom) int { return strings.Compare(a.ProjectId, b.ProjectId) })
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestBundleReadonlySbomWorkspace_ReadSboms_NoGzip(t *testing.T) {
+	bundlePath := filepath.Join(t.TempDir(), "sboms.tar.gz")
+	os.WriteFile(bundlePath, []byte("plain text"), 0644)
+
+	_, err := CreateBundleReadonlySbomWorkspace(bundlePath, nil).ReadSboms()
+	if err == nil || !strings.Contains(err.Error(), "not gzip compressed") {
+		t.Errorf("expected gzip error, got %v", err)
+	}
+}
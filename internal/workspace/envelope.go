@@ -0,0 +1,152 @@
+package workspace
+
+import (
+	"central-cyclone/internal/models"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// SbomSchemaVersion is the version of the envelope written by SaveSbom. Files with a newer version are rejected,
+// files without a version are migrated from the plain SBOM format of earlier releases.
+const SbomSchemaVersion = 1
+
+// SbomEnvelope wraps a BOM with everything required to upload it, without requiring the config
+type SbomEnvelope struct {
+	SchemaVersion int             `json:"schemaVersion"`
+	Generator     string          `json:"generator,omitempty"`
+	Project       EnvelopeProject `json:"project"`
+	Source        EnvelopeSource  `json:"source"`
+	CreatedAt     time.Time       `json:"createdAt"`
+	Sha256        string          `json:"sha256"` // Hex encoded checksum of the BOM
+	Bom           string          `json:"bom"`
+}
+
+// EnvelopeProject identifies the DependencyTrack project either by its UUID or by name and version
+type EnvelopeProject struct {
+	Uuid       string                `json:"uuid,omitempty"`
+	Name       string                `json:"name,omitempty"`
+	Version    string                `json:"version,omitempty"`
+	Type       string                `json:"type,omitempty"`
+	AutoCreate bool                  `json:"autoCreate,omitempty"`
+	Parent     *models.ProjectParent `json:"parent,omitempty"`
+	Tags       []string              `json:"tags,omitempty"`
+}
+
+// EnvelopeSource is the revision of the repository the BOM was created from
+type EnvelopeSource struct {
+	RepoUrl string `json:"repoUrl,omitempty"`
+	Commit  string `json:"commit,omitempty"`
+}
+
+// legacySbom is the plain models.Sbom written before the envelope was introduced
+type legacySbom struct {
+	ProjectId   string `json:"projectId"`
+	ProjectType string `json:"projectType"`
+	Data        string `json:"data"`
+}
+
+func newSbomEnvelope(sbom models.Sbom, createdAt time.Time) SbomEnvelope {
+	return SbomEnvelope{
+		SchemaVersion: SbomSchemaVersion,
+		Generator:     sbom.Generator,
+		Project: EnvelopeProject{
+			Uuid:       sbom.ProjectId,
+			Name:       sbom.ProjectName,
+			Version:    sbom.ProjectVersion,
+			Type:       sbom.ProjectType,
+			AutoCreate: sbom.AutoCreate,
+			Parent:     sbom.Parent,
+			Tags:       sbom.Tags,
+		},
+		Source:    EnvelopeSource{RepoUrl: sbom.RepoUrl, Commit: sbom.Commit},
+		CreatedAt: createdAt.UTC(),
+		Sha256:    checksum(sbom.Data),
+		Bom:       sbom.Data,
+	}
+}
+
+// encodeSbom wraps the SBOM into an envelope of the current schema version
+func encodeSbom(sbom models.Sbom, createdAt time.Time) ([]byte, error) {
+	data, err := json.MarshalIndent(newSbomEnvelope(sbom, createdAt), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal SBOM: %w", err)
+	}
+	return data, nil
+}
+
+// decodeSbom reads an envelope and verifies it. Files without a schema version are migrated.
+func decodeSbom(name string, data []byte) (models.Sbom, error) {
+	var envelope SbomEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return models.Sbom{}, fmt.Errorf("not a valid SBOM file: %w", err)
+	}
+
+	switch {
+	case envelope.SchemaVersion == 0:
+		migrated, err := migrateLegacySbom(data)
+		if err != nil {
+			return models.Sbom{}, err
+		}
+		slog.Warn("Migrated SBOM file without schema version, its checksum cannot be verified", "file", name)
+		envelope = migrated
+	case envelope.SchemaVersion > SbomSchemaVersion:
+		return models.Sbom{}, fmt.Errorf("schema version %d is not supported, this version of central-cyclone supports up to %d, please upgrade", envelope.SchemaVersion, SbomSchemaVersion)
+	case envelope.SchemaVersion < 0:
+		return models.Sbom{}, fmt.Errorf("invalid schema version %d", envelope.SchemaVersion)
+	}
+
+	if err := envelope.validate(); err != nil {
+		return models.Sbom{}, err
+	}
+
+	return models.Sbom{
+		ProjectId:      envelope.Project.Uuid,
+		ProjectName:    envelope.Project.Name,
+		ProjectVersion: envelope.Project.Version,
+		ProjectType:    envelope.Project.Type,
+		AutoCreate:     envelope.Project.AutoCreate,
+		Parent:         envelope.Project.Parent,
+		Tags:           envelope.Project.Tags,
+		Generator:      envelope.Generator,
+		RepoUrl:        envelope.Source.RepoUrl,
+		Commit:         envelope.Source.Commit,
+		Data:           envelope.Bom,
+	}, nil
+}
+
+// migrateLegacySbom converts the plain SBOM format, which carried no checksum, into an envelope
+func migrateLegacySbom(data []byte) (SbomEnvelope, error) {
+	var legacy legacySbom
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return SbomEnvelope{}, fmt.Errorf("not a valid legacy SBOM file: %w", err)
+	}
+	if legacy.Data == "" {
+		return SbomEnvelope{}, fmt.Errorf("file has no schema version and is no legacy SBOM file")
+	}
+	return newSbomEnvelope(models.Sbom{ProjectId: legacy.ProjectId, ProjectType: legacy.ProjectType, Data: legacy.Data}, time.Time{}), nil
+}
+
+func (e SbomEnvelope) validate() error {
+	if e.Bom == "" {
+		return fmt.Errorf("envelope contains no bom")
+	}
+	if e.Sha256 == "" {
+		return fmt.Errorf("envelope contains no checksum")
+	}
+	if actual := checksum(e.Bom); actual != e.Sha256 {
+		return fmt.Errorf("checksum mismatch, expected %s but bom has %s", e.Sha256, actual)
+	}
+	if e.Project.Uuid == "" && (e.Project.Name == "" || e.Project.Version == "") {
+		return fmt.Errorf("envelope identifies no project, either a uuid or a name and version are required")
+	}
+	return nil
+}
+
+func checksum(bom string) string {
+	sum := sha256.Sum256([]byte(bom))
+	return hex.EncodeToString(sum[:])
+}
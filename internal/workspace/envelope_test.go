@@ -0,0 +1,122 @@
+package workspace
+
+import (
+	"central-cyclone/internal/models"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testBom = `{"bomFormat":"CycloneDX","specVersion":"1.6"}`
+
+func TestEncodeSbom_RoundTrip(t *testing.T) {
+	sbom := models.Sbom{
+		ProjectId:   "uuid-1",
+		ProjectType: "go",
+		Generator:   "syft",
+		RepoUrl:     "https://github.com/org/repo.git",
+		Commit:      "abc123",
+		Data:        testBom,
+	}
+
+	data, err := encodeSbom(sbom, time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var envelope SbomEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if envelope.SchemaVersion != SbomSchemaVersion {
+		t.Errorf("expected schema version %d, got %d", SbomSchemaVersion, envelope.SchemaVersion)
+	}
+	if envelope.Sha256 != checksum(testBom) {
+		t.Errorf("unexpected checksum %s", envelope.Sha256)
+	}
+
+	got, err := decodeSbom("sbom.json", data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, sbom) {
+		t.Errorf("expected %+v, got %+v", sbom, got)
+	}
+}
+
+func TestDecodeSbom_MigratesLegacyFormat(t *testing.T) {
+	legacy, _ := json.Marshal(legacySbom{ProjectId: "uuid-1", ProjectType: "java", Data: testBom})
+
+	got, err := decodeSbom("sbom.json", legacy)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := models.Sbom{ProjectId: "uuid-1", ProjectType: "java", Data: testBom}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestDecodeSbom_Rejects(t *testing.T) {
+	valid := newSbomEnvelope(models.Sbom{ProjectId: "uuid-1", Data: testBom}, time.Now())
+
+	tests := []struct {
+		name    string
+		modify  func(e *SbomEnvelope)
+		raw     string
+		wantErr string
+	}{
+		{name: "newer schema version", modify: func(e *SbomEnvelope) { e.SchemaVersion = SbomSchemaVersion + 1 }, wantErr: "please upgrade"},
+		{name: "negative schema version", modify: func(e *SbomEnvelope) { e.SchemaVersion = -1 }, wantErr: "invalid schema version"},
+		{name: "checksum mismatch", modify: func(e *SbomEnvelope) { e.Bom = `{"bomFormat":"CycloneDX"}` }, wantErr: "checksum mismatch"},
+		{name: "missing checksum", modify: func(e *SbomEnvelope) { e.Sha256 = "" }, wantErr: "no checksum"},
+		{name: "missing project", modify: func(e *SbomEnvelope) { e.Project = EnvelopeProject{Name: "app"} }, wantErr: "identifies no project"},
+		{name: "no json", raw: "not json", wantErr: "not a valid SBOM file"},
+		{name: "unknown json", raw: `{"foo":"bar"}`, wantErr: "no legacy SBOM file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := []byte(tt.raw)
+			if tt.modify != nil {
+				envelope := valid
+				tt.modify(&envelope)
+				data, _ = json.Marshal(envelope)
+			}
+
+			_, err := decodeSbom("sbom.json", data)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestDecodeSbom_AcceptsProjectNameAndVersion(t *testing.T) {
+	data, _ := encodeSbom(models.Sbom{ProjectName: "app", ProjectVersion: "1.0.0", Data: testBom}, time.Now())
+
+	got, err := decodeSbom("sbom.json", data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.ProjectName != "app" || got.ProjectVersion != "1.0.0" {
+		t.Errorf("unexpected project %s@%s", got.ProjectName, got.ProjectVersion)
+	}
+}
+
+func TestLocalReadonlySbomWorkspace_ReadSboms_FailsOnInvalidFile(t *testing.T) {
+	dir := t.TempDir()
+	data, _ := encodeSbom(models.Sbom{ProjectId: "uuid-1", Data: testBom}, time.Now())
+	os.WriteFile(filepath.Join(dir, "sbom_uuid-1.json"), data, 0644)
+	os.WriteFile(filepath.Join(dir, "sbom_uuid-2.json"), []byte("broken"), 0644)
+	os.WriteFile(filepath.Join(dir, "README.md"), []byte("ignored"), 0644)
+
+	_, err := CreateLocalReadonlySbomWorkspace(dir, DefaultSBOMNamer{}, DefaultRepoMapper{}, nil).ReadSboms()
+	if err == nil || !strings.Contains(err.Error(), "sbom_uuid-2.json") {
+		t.Errorf("expected error naming the invalid file, got %v", err)
+	}
+}
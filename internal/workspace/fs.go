@@ -7,6 +7,7 @@ import (
 
 type FSHelper interface {
 	CreateFolderIfNotExists(path string) error
+	CreateTempFolder(parent string, prefix string) (string, error)
 	ListFiles(path string) ([]string, error)
 	RemoveAll(path string) error
 	WriteFile(path string, data []byte) error
@@ -23,6 +24,18 @@ func (h LocalFSHelper) CreateFolderIfNotExists(path string) error {
 	return nil
 }
 
+// CreateTempFolder creates a new folder with a unique name starting with the given prefix within parent
+func (h LocalFSHelper) CreateTempFolder(parent string, prefix string) (string, error) {
+	if err := h.CreateFolderIfNotExists(parent); err != nil {
+		return "", err
+	}
+	path, err := os.MkdirTemp(parent, prefix)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp folder in '%s': %w", parent, err)
+	}
+	return path, nil
+}
+
 func (h LocalFSHelper) ListFiles(path string) ([]string, error) {
 	entries, err := os.ReadDir(path)
 	if err != nil {
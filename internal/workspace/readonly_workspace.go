@@ -2,10 +2,12 @@ package workspace
 
 import (
 	"central-cyclone/internal/models"
-	"encoding/json"
+	"crypto/ed25519"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
+	"path/filepath"
 	"strings"
 )
 
@@ -18,16 +20,20 @@ type LocalReadonlySbomWorkspace struct {
 	fs         FSHelper
 	sbomNamer  SBOMNamer
 	repoMapper RepoURLMapper
+	verifyKey  ed25519.PublicKey
 }
 
-func CreateLocalReadonlySbomWorkspace(path string, sbomNamer SBOMNamer, repoMapper RepoURLMapper) ReadonlySbomWorkspace {
-	return LocalReadonlySbomWorkspace{path: path, fs: LocalFSHelper{}, sbomNamer: sbomNamer, repoMapper: repoMapper}
+// CreateLocalReadonlySbomWorkspace reads the SBOMs of a folder. If a verify key is given, the folder requires a signed manifest
+// listing exactly its SBOM files.
+func CreateLocalReadonlySbomWorkspace(path string, sbomNamer SBOMNamer, repoMapper RepoURLMapper, verifyKey ed25519.PublicKey) ReadonlySbomWorkspace {
+	return LocalReadonlySbomWorkspace{path: path, fs: LocalFSHelper{}, sbomNamer: sbomNamer, repoMapper: repoMapper, verifyKey: verifyKey}
 }
 
+// ReadSboms reads all SBOM envelopes of the folder. Files written by earlier releases without a schema version are migrated,
+// any file that is invalid, has a newer schema version, does not match its checksum or the signed manifest fails the read.
+//
 // Note: The current behavior is not the best in terms of performance, as it reads all SBOMs at once.
 // In the future, we could inject an uploader, such that the garbarge collector can come in earlier.
-// Or have an own file format / json format that holds all required informations. This would allow us to just read and upload file after file
-// without requiring the config.
 func (w LocalReadonlySbomWorkspace) ReadSboms() ([]models.Sbom, error) {
 
 	filePaths, err := w.fs.ListFiles(w.path)
@@ -35,9 +41,14 @@ func (w LocalReadonlySbomWorkspace) ReadSboms() ([]models.Sbom, error) {
 		return nil, err
 	}
 
-	var sboms []models.Sbom
+	var sbomPaths []string
+	files := map[string][]byte{}
 
 	for _, filePath := range filePaths {
+		name := filepath.Base(filePath)
+		if name == manifestName || name == manifestName+signatureSuffix {
+			continue
+		}
 		if !strings.HasSuffix(filePath, ".json") {
 			slog.Info("Skipping non-JSON file", "file", filePath)
 			continue
@@ -48,13 +59,48 @@ func (w LocalReadonlySbomWorkspace) ReadSboms() ([]models.Sbom, error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to read file %s: %w", filePath, err)
 		}
+		sbomPaths = append(sbomPaths, filePath)
+		files[name] = data
+	}
 
-		sbom := models.Sbom{}
+	if w.verifyKey != nil {
+		if err := w.verifyManifest(files); err != nil {
+			return nil, err
+		}
+	}
 
-		json.Unmarshal(data, &sbom)
+	var sboms []models.Sbom
+	for _, filePath := range sbomPaths {
+		sbom, err := decodeSbom(filePath, files[filepath.Base(filePath)])
+		if err != nil {
+			return nil, fmt.Errorf("invalid SBOM file %s: %w", filePath, err)
+		}
 
 		sboms = append(sboms, sbom)
 	}
 
 	return sboms, nil
 }
+
+// verifyManifest checks the SBOM files of the folder against its signed manifest
+func (w LocalReadonlySbomWorkspace) verifyManifest(files map[string][]byte) error {
+	manifestPath := filepath.Join(w.path, manifestName)
+	manifestData, err := readOptionalFile(manifestPath)
+	if err != nil {
+		return fmt.Errorf("failed to read manifest %s: %w", manifestPath, err)
+	}
+	signature, err := readOptionalFile(manifestPath + signatureSuffix)
+	if err != nil {
+		return fmt.Errorf("failed to read signature of %s: %w", manifestPath, err)
+	}
+	return verifyManifest(w.verifyKey, manifestData, signature, files)
+}
+
+// readOptionalFile reads a file, which returns no data and no error if the file does not exist
+func readOptionalFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return data, err
+}
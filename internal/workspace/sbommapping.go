@@ -4,6 +4,7 @@ import (
 	"central-cyclone/internal/models"
 	"fmt"
 	"path/filepath"
+	"regexp"
 )
 
 type SBOMNamer interface {
@@ -12,7 +13,16 @@ type SBOMNamer interface {
 
 type DefaultSBOMNamer struct{}
 
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// GenerateSBOMPath names the file after the project id, or after name and version if no id is set.
+// As sanitizing name and version is lossy, a short hash of both keeps the file names of different projects apart.
 func (n DefaultSBOMNamer) GenerateSBOMPath(sbomsDir string, sbom models.Sbom) string {
 	sbomFileName := fmt.Sprintf("sbom_%s.json", sbom.ProjectId)
+	if sbom.ProjectId == "" {
+		name := unsafeFileNameChars.ReplaceAllString(sbom.ProjectName+"_"+sbom.ProjectVersion, "-")
+		hash := checksum(sbom.ProjectName + "\x00" + sbom.ProjectVersion)[:8]
+		sbomFileName = fmt.Sprintf("sbom_%s_%s.json", name, hash)
+	}
 	return filepath.Join(sbomsDir, sbomFileName)
 }
@@ -19,3 +19,33 @@ func TestDefaultSBOMNamer_GenerateSBOMPath(t *testing.T) {
 		t.Errorf("GenerateSBOMPath() = %q, want %q", got, want)
 	}
 }
+
+func TestDefaultSBOMNamer_GenerateSBOMPath_NameAndVersion(t *testing.T) {
+	namer := DefaultSBOMNamer{}
+	sbom := models.Sbom{ProjectName: "org/app", ProjectVersion: "1.0 beta"}
+	got := namer.GenerateSBOMPath("/path/to/sboms", sbom)
+	want := filepath.Join("/path/to/sboms", "sbom_org-app_1.0-beta_76eae37b.json")
+	if got != want {
+		t.Errorf("GenerateSBOMPath() = %q, want %q", got, want)
+	}
+}
+
+func TestDefaultSBOMNamer_GenerateSBOMPath_DistinguishesSanitizedNames(t *testing.T) {
+	tests := []struct {
+		name  string
+		sboms [2]models.Sbom
+	}{
+		{name: "unsafe characters", sboms: [2]models.Sbom{{ProjectName: "org/app", ProjectVersion: "1.0"}, {ProjectName: "org-app", ProjectVersion: "1.0"}}},
+		{name: "separator", sboms: [2]models.Sbom{{ProjectName: "a_b", ProjectVersion: "c"}, {ProjectName: "a", ProjectVersion: "b_c"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first := DefaultSBOMNamer{}.GenerateSBOMPath("", tt.sboms[0])
+			second := DefaultSBOMNamer{}.GenerateSBOMPath("", tt.sboms[1])
+			if first == second {
+				t.Errorf("expected different paths, got %q for both", first)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package workspace
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const (
+	// manifestName is the name of the file listing the name and checksum of every saved SBOM file.
+	// It does not end with .json, so it is never read as an SBOM.
+	manifestName = "sboms.manifest"
+	// signatureSuffix is appended to the name of the manifest to get the name of its detached signature
+	signatureSuffix = ".sig"
+)
+
+// manifest lists the SBOM files of a folder or bundle. As only the manifest is signed, deleting an SBOM file,
+// adding one or replacing it with an older signed one fails the verification.
+type manifest struct {
+	Files []manifestEntry `json:"files"`
+}
+
+type manifestEntry struct {
+	Name   string `json:"name"`
+	Sha256 string `json:"sha256"`
+}
+
+// LoadSigningKey reads an ed25519 private key from a PKCS #8 PEM file, e.g. created with
+// `openssl genpkey -algorithm ed25519`
+func LoadSigningKey(path string) (ed25519.PrivateKey, error) {
+	block, err := readPem(path, "PRIVATE KEY")
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key %s: %w", path, err)
+	}
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key %s is no ed25519 key", path)
+	}
+	return privateKey, nil
+}
+
+// LoadVerifyKey reads an ed25519 public key from a PKIX PEM file, e.g. created with `openssl pkey -pubout`
+func LoadVerifyKey(path string) (ed25519.PublicKey, error) {
+	block, err := readPem(path, "PUBLIC KEY")
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key %s: %w", path, err)
+	}
+	publicKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key %s is no ed25519 key", path)
+	}
+	return publicKey, nil
+}
+
+func readPem(path string, blockType string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key %s: %w", path, err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != blockType {
+		return nil, fmt.Errorf("key %s contains no PEM block of type %s", path, blockType)
+	}
+	return block, nil
+}
+
+// manifestWriter records the checksums of the SBOM files saved to a folder and rewrites the signed manifest after every save.
+// It is shared by all copies of a workspace, as SBOMs are saved concurrently.
+type manifestWriter struct {
+	key       ed25519.PrivateKey
+	mu        sync.Mutex
+	checksums map[string]string // SBOM file name to its sha256
+}
+
+func newManifestWriter(key ed25519.PrivateKey) *manifestWriter {
+	return &manifestWriter{key: key, checksums: map[string]string{}}
+}
+
+// add records the saved SBOM file and writes the manifest listing all saved files and its signature into dir
+func (m *manifestWriter) add(fs FSHelper, dir string, name string, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.checksums[name] = checksum(string(data))
+	content := manifest{Files: []manifestEntry{}}
+	for _, fileName := range slices.Sorted(maps.Keys(m.checksums)) {
+		content.Files = append(content.Files, manifestEntry{Name: fileName, Sha256: m.checksums[fileName]})
+	}
+	manifestData, err := json.MarshalIndent(content, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	manifestPath := filepath.Join(dir, manifestName)
+	if err := fs.WriteFile(manifestPath, manifestData); err != nil {
+		return fmt.Errorf("failed to save manifest %s: %w", manifestPath, err)
+	}
+	if err := fs.WriteFile(manifestPath+signatureSuffix, sign(m.key, manifestData)); err != nil {
+		return fmt.Errorf("failed to save signature of %s: %w", manifestPath, err)
+	}
+	return nil
+}
+
+// reset forgets the saved files, after the folder was cleared
+func (m *manifestWriter) reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.checksums = map[string]string{}
+}
+
+// sign creates the base64 encoded detached signature of the file content
+func sign(key ed25519.PrivateKey, data []byte) []byte {
+	return []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(key, data)))
+}
+
+// verifyManifest checks the signature of the manifest and that it lists exactly the given SBOM files,
+// keyed by their name, with matching checksums. A missing manifest or signature is an error.
+func verifyManifest(key ed25519.PublicKey, manifestData []byte, signature []byte, files map[string][]byte) error {
+	if manifestData == nil {
+		return fmt.Errorf("SBOMs are not signed, expected manifest %s", manifestName)
+	}
+	if err := verifySignature(key, manifestName, manifestData, signature); err != nil {
+		return err
+	}
+
+	var content manifest
+	if err := json.Unmarshal(manifestData, &content); err != nil {
+		return fmt.Errorf("invalid manifest %s: %w", manifestName, err)
+	}
+	listed := map[string]bool{}
+	for _, entry := range content.Files {
+		listed[entry.Name] = true
+		data, ok := files[entry.Name]
+		if !ok {
+			return fmt.Errorf("%s is listed in the manifest but missing, it may have been deleted", entry.Name)
+		}
+		if checksum(string(data)) != entry.Sha256 {
+			return fmt.Errorf("checksum of %s does not match the manifest, the file may have been tampered with or replaced", entry.Name)
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		if !listed[name] {
+			return fmt.Errorf("%s is not listed in the manifest, the file may have been added", name)
+		}
+	}
+	return nil
+}
+
+// verifySignature checks the detached signature of a file. A missing signature is an error.
+func verifySignature(key ed25519.PublicKey, name string, data []byte, signature []byte) error {
+	if signature == nil {
+		return fmt.Errorf("%s is not signed, expected signature %s", name, name+signatureSuffix)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil {
+		return fmt.Errorf("signature of %s is not base64 encoded: %w", name, err)
+	}
+	if !ed25519.Verify(key, data, decoded) {
+		return fmt.Errorf("signature of %s does not match, the file may have been tampered with", name)
+	}
+	return nil
+}
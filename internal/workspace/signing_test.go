@@ -0,0 +1,156 @@
+package workspace
+
+import (
+	"central-cyclone/internal/models"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeKeyPair writes a new ed25519 key pair as PEM files and returns their paths
+func writeKeyPair(t *testing.T) (string, string) {
+	t.Helper()
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	privateBytes, _ := x509.MarshalPKCS8PrivateKey(privateKey)
+	publicBytes, _ := x509.MarshalPKIXPublicKey(publicKey)
+
+	dir := t.TempDir()
+	privatePath := filepath.Join(dir, "key.pem")
+	publicPath := filepath.Join(dir, "key.pub")
+	os.WriteFile(privatePath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateBytes}), 0600)
+	os.WriteFile(publicPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicBytes}), 0644)
+	return privatePath, publicPath
+}
+
+// saveSignedSboms saves the SBOMs with a signing workspace writing into dir
+func saveSignedSboms(t *testing.T, dir string, privatePath string, sboms ...models.Sbom) {
+	t.Helper()
+	signingKey, err := LoadSigningKey(privatePath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	w := localWorkspace{sbomsPath: dir, fs: LocalFSHelper{}, namer: DefaultSBOMNamer{}, manifest: newManifestWriter(signingKey)}
+	for _, sbom := range sboms {
+		if err := w.SaveSbom(sbom); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
+func TestLoadKeys_RejectsWrongPemType(t *testing.T) {
+	privatePath, publicPath := writeKeyPair(t)
+
+	if _, err := LoadSigningKey(publicPath); err == nil {
+		t.Error("expected error loading a public key as signing key")
+	}
+	if _, err := LoadVerifyKey(privatePath); err == nil {
+		t.Error("expected error loading a private key as verify key")
+	}
+}
+
+func TestLocalReadonlySbomWorkspace_ReadSboms_VerifiesSignatures(t *testing.T) {
+	privatePath, publicPath := writeKeyPair(t)
+	_, otherPublicPath := writeKeyPair(t)
+	first := models.Sbom{ProjectId: "uuid-1", ProjectType: "go", Data: testBom}
+	second := models.Sbom{ProjectId: "uuid-2", ProjectType: "js", Data: testBom}
+	firstPath := filepath.Base(DefaultSBOMNamer{}.GenerateSBOMPath("", first))
+	secondPath := filepath.Base(DefaultSBOMNamer{}.GenerateSBOMPath("", second))
+
+	// An older SBOM of the second project, which was signed by an earlier run
+	olderDir := t.TempDir()
+	saveSignedSboms(t, olderDir, privatePath, models.Sbom{ProjectId: "uuid-2", ProjectType: "js", Data: `{"bomFormat":"CycloneDX","specVersion":"1.4"}`})
+	older, _ := os.ReadFile(filepath.Join(olderDir, secondPath))
+
+	tests := []struct {
+		name      string
+		verifyKey string
+		modify    func(dir string)
+		wantErr   string
+	}{
+		{name: "valid signature", verifyKey: publicPath},
+		{name: "no verify key ignores signatures", modify: func(dir string) { os.Remove(filepath.Join(dir, manifestName+signatureSuffix)) }},
+		{name: "other key", verifyKey: otherPublicPath, wantErr: "does not match"},
+		{name: "missing signature", verifyKey: publicPath, modify: func(dir string) { os.Remove(filepath.Join(dir, manifestName+signatureSuffix)) }, wantErr: "is not signed"},
+		{name: "missing manifest", verifyKey: publicPath, modify: func(dir string) { os.Remove(filepath.Join(dir, manifestName)) }, wantErr: "expected manifest"},
+		{name: "tampered file", verifyKey: publicPath, modify: func(dir string) {
+			path := filepath.Join(dir, firstPath)
+			data, _ := os.ReadFile(path)
+			os.WriteFile(path, []byte(strings.Replace(string(data), "uuid-1", "uuid-3", 1)), 0644)
+		}, wantErr: "checksum of " + firstPath + " does not match the manifest"},
+		{name: "file replaced with older signed file", verifyKey: publicPath, modify: func(dir string) {
+			os.WriteFile(filepath.Join(dir, secondPath), older, 0644)
+		}, wantErr: "checksum of " + secondPath + " does not match the manifest"},
+		{name: "deleted file", verifyKey: publicPath, modify: func(dir string) { os.Remove(filepath.Join(dir, secondPath)) }, wantErr: secondPath + " is listed in the manifest but missing"},
+		{name: "added file", verifyKey: publicPath, modify: func(dir string) {
+			os.WriteFile(filepath.Join(dir, "sbom_uuid-3.json"), older, 0644)
+		}, wantErr: "sbom_uuid-3.json is not listed in the manifest"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			saveSignedSboms(t, dir, privatePath, first, second)
+			if tt.modify != nil {
+				tt.modify(dir)
+			}
+
+			var verifyKey ed25519.PublicKey
+			if tt.verifyKey != "" {
+				verifyKey, _ = LoadVerifyKey(tt.verifyKey)
+			}
+			got, err := CreateLocalReadonlySbomWorkspace(dir, DefaultSBOMNamer{}, DefaultRepoMapper{}, verifyKey).ReadSboms()
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, []models.Sbom{first, second}) {
+				t.Errorf("expected %+v, got %+v", []models.Sbom{first, second}, got)
+			}
+		})
+	}
+}
+
+func TestBundleReadonlySbomWorkspace_ReadSboms_VerifiesSignatures(t *testing.T) {
+	privatePath, publicPath := writeKeyPair(t)
+	_, otherPublicPath := writeKeyPair(t)
+	sbomsDir := t.TempDir()
+	saveSignedSboms(t, sbomsDir, privatePath, models.Sbom{ProjectId: "uuid-1", Data: testBom}, models.Sbom{ProjectId: "uuid-2", Data: testBom})
+	bundlePath := filepath.Join(t.TempDir(), "sboms.tar.gz")
+	if err := WriteBundle(sbomsDir, bundlePath); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	verifyKey, _ := LoadVerifyKey(publicPath)
+	sboms, err := CreateBundleReadonlySbomWorkspace(bundlePath, verifyKey).ReadSboms()
+	if err != nil || len(sboms) != 2 {
+		t.Errorf("expected two verified sboms, got %d, error %v", len(sboms), err)
+	}
+
+	otherKey, _ := LoadVerifyKey(otherPublicPath)
+	if _, err := CreateBundleReadonlySbomWorkspace(bundlePath, otherKey).ReadSboms(); err == nil {
+		t.Error("expected signature error for other key")
+	}
+
+	os.Remove(DefaultSBOMNamer{}.GenerateSBOMPath(sbomsDir, models.Sbom{ProjectId: "uuid-2"}))
+	if err := WriteBundle(sbomsDir, bundlePath); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := CreateBundleReadonlySbomWorkspace(bundlePath, verifyKey).ReadSboms(); err == nil || !strings.Contains(err.Error(), "listed in the manifest but missing") {
+		t.Errorf("expected error for the SBOM missing in the bundle, got %v", err)
+	}
+}
@@ -2,33 +2,41 @@ package workspace
 
 import (
 	"central-cyclone/internal/models"
-	"encoding/json"
+	"crypto/ed25519"
 	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 )
 
 const (
-	workspacePath = "workfolder"
-	repoFolder    = "repos"
-	sbomFolder    = "sboms"
+	workspacePath  = "workfolder"
+	repoFolder     = "repos"
+	worktreeFolder = "worktrees"
+	sbomFolder     = "sboms"
 )
 
 type localWorkspace struct {
-	path       string
-	reposPath  string
-	sbomsPath  string
-	fs         FSHelper
-	namer      SBOMNamer
-	repoMapper RepoURLMapper
+	path          string
+	reposPath     string
+	worktreesPath string
+	sbomsPath     string
+	fs            FSHelper
+	namer         SBOMNamer
+	repoMapper    RepoURLMapper
+	manifest      *manifestWriter // Optional, lists every saved SBOM in a signed manifest
 }
 
 type Workspace interface {
 	Clear() error
 	CreateRepoFolder(repiUrl string) (string, error)
+	CreateWorktreeFolder(repoUrl string) (string, error)
 	SaveSbom(sbom models.Sbom) error
 	ReadFileFromRepo(repoPath string, relativePath string) ([]byte, error)
+	GlobFilesInRepo(repoPath string, pattern string) ([]string, error)
 }
 
 func (w localWorkspace) CreateRepoFolder(repoUrl string) (string, error) {
@@ -46,26 +54,53 @@ func (w localWorkspace) CreateRepoFolder(repoUrl string) (string, error) {
 
 }
 
+// CreateWorktreeFolder creates a new, uniquely named folder for a single checkout of the given repo
+func (w localWorkspace) CreateWorktreeFolder(repoUrl string) (string, error) {
+	folderName, err := w.repoMapper.GetFolderName(repoUrl)
+	if err != nil {
+		return "", fmt.Errorf("failed to get folder name from repo URL: %w", err)
+	}
+
+	targetDir, err := w.fs.CreateTempFolder(w.worktreesPath, folderName+"-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create worktree dir: %w", err)
+	}
+
+	return targetDir, nil
+}
+
 func (w localWorkspace) Clear() error {
 	if err := w.fs.RemoveAll(w.reposPath); err != nil {
 		return fmt.Errorf("failed to clear repos directory: %w", err)
 	}
 
+	if err := w.fs.RemoveAll(w.worktreesPath); err != nil {
+		return fmt.Errorf("failed to clear worktrees directory: %w", err)
+	}
+
 	if err := w.fs.RemoveAll(w.sbomsPath); err != nil {
 		return fmt.Errorf("failed to clear sboms directory: %w", err)
 	}
+	if w.manifest != nil {
+		w.manifest.reset()
+	}
 	return nil
 }
 
 func (w localWorkspace) SaveSbom(sbom models.Sbom) error {
 	sbomPath := w.namer.GenerateSBOMPath(w.sbomsPath, sbom)
-	data, err := json.Marshal(sbom)
+	data, err := encodeSbom(sbom, time.Now())
 	if err != nil {
-		return fmt.Errorf("failed to marshal SBOM: %w", err)
+		return err
 	}
 	if err := w.fs.WriteFile(sbomPath, data); err != nil {
 		return fmt.Errorf("failed to save SBOM to %s: %w", sbomPath, err)
 	}
+	if w.manifest != nil {
+		if err := w.manifest.add(w.fs, w.sbomsPath, filepath.Base(sbomPath), data); err != nil {
+			return err
+		}
+	}
 	slog.Info("💾 Saved SBOM", "path", sbomPath)
 	return nil
 }
@@ -98,33 +133,105 @@ func (w localWorkspace) ReadFileFromRepo(repoPath string, relativePath string) (
 	return data, nil
 }
 
-func CreateLocalWorkspace() (Workspace, error) {
+// GlobFilesInRepo returns the paths of all files within a cloned repository matching the glob pattern.
+// The pattern and the returned paths are relative to the repository and use forward slashes.
+func (w localWorkspace) GlobFilesInRepo(repoPath string, pattern string) ([]string, error) {
+	if repoPath == "" {
+		return nil, fmt.Errorf("repoPath cannot be empty")
+	}
+	if pattern == "" {
+		return nil, fmt.Errorf("pattern cannot be empty")
+	}
+	if slices.Contains(strings.Split(pattern, "/"), "..") {
+		return nil, fmt.Errorf("path traversal detected: %s is outside repository", pattern)
+	}
+
+	matches, err := filepath.Glob(filepath.Join(repoPath, filepath.FromSlash(pattern)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %s: %w", pattern, err)
+	}
+
+	var files []string
+	for _, match := range matches {
+		info, err := os.Stat(match)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		relativePath, err := filepath.Rel(repoPath, match)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get relative path of %s: %w", match, err)
+		}
+		files = append(files, filepath.ToSlash(relativePath))
+	}
+
+	return files, nil
+}
+
+// GetWorkFolderPath returns the path of the local workfolder within the users home directory
+func GetWorkFolderPath() (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user home directory: %w", err)
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".central-cyclone", workspacePath), nil
+}
+
+// GetSbomsFolderPath returns the path of the folder the local workspace saves SBOMs to
+func GetSbomsFolderPath() (string, error) {
+	workFolderPath, err := GetWorkFolderPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(workFolderPath, sbomFolder), nil
+}
+
+func CreateLocalWorkspace() (Workspace, error) {
+	return createLocalWorkspace()
+}
+
+// CreateSigningLocalWorkspace creates a local workspace, which lists every saved SBOM with its checksum in a manifest
+// and writes a detached signature of the manifest
+func CreateSigningLocalWorkspace(signingKey ed25519.PrivateKey) (Workspace, error) {
+	w, err := createLocalWorkspace()
+	if err != nil {
+		return nil, err
+	}
+	w.manifest = newManifestWriter(signingKey)
+	return w, nil
+}
+
+func createLocalWorkspace() (localWorkspace, error) {
+	fullWorkFolderPath, err := GetWorkFolderPath()
+	if err != nil {
+		return localWorkspace{}, err
 	}
-	fullWorkFolderPath := filepath.Join(homeDir, ".central-cyclone", workspacePath)
 
 	fs := LocalFSHelper{}
 	if err := fs.CreateFolderIfNotExists(fullWorkFolderPath); err != nil {
-		return nil, fmt.Errorf("could not create workfolder: %w", err)
+		return localWorkspace{}, fmt.Errorf("could not create workfolder: %w", err)
 	}
 
 	fullReposPath := filepath.Join(fullWorkFolderPath, repoFolder)
 	if err := fs.CreateFolderIfNotExists(fullReposPath); err != nil {
-		return nil, fmt.Errorf("could not create repos folder: %w", err)
+		return localWorkspace{}, fmt.Errorf("could not create repos folder: %w", err)
+	}
+
+	fullWorktreesPath := filepath.Join(fullWorkFolderPath, worktreeFolder)
+	if err := fs.CreateFolderIfNotExists(fullWorktreesPath); err != nil {
+		return localWorkspace{}, fmt.Errorf("could not create worktrees folder: %w", err)
 	}
 
 	fullSbomsPath := filepath.Join(fullWorkFolderPath, sbomFolder)
 	if err := fs.CreateFolderIfNotExists(fullSbomsPath); err != nil {
-		return nil, fmt.Errorf("could not create sboms folder: %w", err)
+		return localWorkspace{}, fmt.Errorf("could not create sboms folder: %w", err)
 	}
 	return localWorkspace{
-		path:       fullWorkFolderPath,
-		reposPath:  fullReposPath,
-		sbomsPath:  fullSbomsPath,
-		fs:         fs,
-		namer:      DefaultSBOMNamer{},
-		repoMapper: DefaultRepoMapper{},
+		path:          fullWorkFolderPath,
+		reposPath:     fullReposPath,
+		worktreesPath: fullWorktreesPath,
+		sbomsPath:     fullSbomsPath,
+		fs:            fs,
+		namer:         DefaultSBOMNamer{},
+		repoMapper:    DefaultRepoMapper{},
 	}, nil
 }
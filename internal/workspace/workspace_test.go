@@ -3,6 +3,8 @@ package workspace
 import (
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -81,3 +83,64 @@ func TestCloneRepoToWorkspace(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateWorktreeFolder_CreatesUniqueFolders(t *testing.T) {
+	tempDir := t.TempDir()
+	w := localWorkspace{
+		path:          tempDir,
+		worktreesPath: filepath.Join(tempDir, "worktrees"),
+		fs:            LocalFSHelper{},
+		repoMapper:    DefaultRepoMapper{},
+	}
+
+	first, err := w.CreateWorktreeFolder("https://github.com/org/repo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, err := w.CreateWorktreeFolder("https://github.com/org/repo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if first == second {
+		t.Errorf("expected unique folders, got %q twice", first)
+	}
+	if filepath.Dir(first) != w.worktreesPath {
+		t.Errorf("expected folder within %q, got %q", w.worktreesPath, first)
+	}
+	if !strings.HasPrefix(filepath.Base(first), "org_repo-") {
+		t.Errorf("expected folder name to start with the repo folder name, got %q", filepath.Base(first))
+	}
+}
+
+func TestGlobFilesInRepo_ReturnsRelativeFiles(t *testing.T) {
+	repoPath := t.TempDir()
+	for _, file := range []string{"apps/basket/dev/values.yaml", "apps/basket/prod/values.yaml", "apps/basket/base/kustomization.yaml"} {
+		fullPath := filepath.Join(repoPath, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+			t.Fatalf("failed to create folder: %v", err)
+		}
+		if err := os.WriteFile(fullPath, []byte("version: 1.0.0"), 0o644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+	// Folders matching the pattern are not returned
+	if err := os.MkdirAll(filepath.Join(repoPath, "apps", "basket", "folder", "values.yaml"), 0o755); err != nil {
+		t.Fatalf("failed to create folder: %v", err)
+	}
+
+	w := localWorkspace{}
+	files, err := w.GlobFilesInRepo(repoPath, "apps/basket/*/values.yaml")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{"apps/basket/dev/values.yaml", "apps/basket/prod/values.yaml"}
+	if !slices.Equal(files, expected) {
+		t.Errorf("expected %v, got %v", expected, files)
+	}
+
+	if _, err := w.GlobFilesInRepo(repoPath, "../*/values.yaml"); err == nil {
+		t.Error("expected error for pattern outside of the repository")
+	}
+}